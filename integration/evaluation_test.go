@@ -209,18 +209,14 @@ func theResolvedBooleanDetailsValueShouldBeTheVariantShouldBeAndTheReasonShouldB
 	if got.Value != value {
 		return fmt.Errorf("expected value to be %t, got %t", value, got.Value)
 	}
-	// TODO: return variant name (see: https://github.com/flipt-io/flipt/discussions/1075)
-	//
-	// if got.Variant != variant {
-	// 	return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
-	// }
-
-	// TODO: We return TARGETING_MATCH here, however flagd returns DEFAULT
-	// currently am not sure which one is correct, so need to follow up with the open-feature team
-	//
-	// if string(got.Reason) != reason {
-	// 	return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
-	// }
+
+	if got.Variant != variant {
+		return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
+	}
+
+	if string(got.Reason) != reason {
+		return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
+	}
 
 	return nil
 }
@@ -249,18 +245,14 @@ func theResolvedStringDetailsValueShouldBeTheVariantShouldBeAndTheReasonShouldBe
 	if got.Value != value {
 		return fmt.Errorf("expected value to be %s, got %s", value, got.Value)
 	}
-	// TODO: return variant name (see: https://github.com/flipt-io/flipt/discussions/1075)
-	//
-	// if got.Variant != variant {
-	// 	return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
-	// }
 
-	// TODO: We return TARGETING_MATCH here, however flagd returns DEFAULT
-	// currently am not sure which one is correct, so need to follow up with the open-feature team
-	//
-	// if string(got.Reason) != reason {
-	// 	return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
-	// }
+	if got.Variant != variant {
+		return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
+	}
+
+	if string(got.Reason) != reason {
+		return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
+	}
 
 	return nil
 }
@@ -289,18 +281,14 @@ func theResolvedIntegerDetailsValueShouldBeTheVariantShouldBeAndTheReasonShouldB
 	if got.Value != value {
 		return fmt.Errorf("expected value to be %d, got %d", value, got.Value)
 	}
-	// TODO: return variant name (see: https://github.com/flipt-io/flipt/discussions/1075)
-	//
-	// if got.Variant != variant {
-	// 	return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
-	// }
 
-	// TODO: We return TARGETING_MATCH here, however flagd returns DEFAULT
-	// currently am not sure which one is correct, so need to follow up with the open-feature team
-	//
-	// if string(got.Reason) != reason {
-	// 	return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
-	// }
+	if got.Variant != variant {
+		return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
+	}
+
+	if string(got.Reason) != reason {
+		return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
+	}
 
 	return nil
 }
@@ -329,18 +317,14 @@ func theResolvedFloatDetailsValueShouldBeTheVariantShouldBeAndTheReasonShouldBe(
 	if got.Value != value {
 		return fmt.Errorf("expected value to be %f, got %f", value, got.Value)
 	}
-	// TODO: return variant name (see: https://github.com/flipt-io/flipt/discussions/1075)
-	//
-	// if got.Variant != variant {
-	// 	return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
-	// }
 
-	// TODO: We return TARGETING_MATCH here, however flagd returns DEFAULT
-	// currently am not sure which one is correct, so need to follow up with the open-feature team
-	//
-	// if string(got.Reason) != reason {
-	// 	return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
-	// }
+	if got.Variant != variant {
+		return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
+	}
+
+	if string(got.Reason) != reason {
+		return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
+	}
 
 	return nil
 }
@@ -406,12 +390,9 @@ func theVariantShouldBeAndTheReasonShouldBe(ctx context.Context, variant, reason
 		return fmt.Errorf("expected variant to be %s, got %s", variant, got.Variant)
 	}
 
-	// TODO: We return TARGETING_MATCH here, however flagd returns DEFAULT
-	// currently am not sure which one is correct, so need to follow up with the open-feature team
-	//
-	// if string(got.Reason) != reason {
-	// 	return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
-	// }
+	if string(got.Reason) != reason {
+		return fmt.Errorf("expected reason to be %s, got %s", reason, got.Reason)
+	}
 
 	return nil
 }
@@ -0,0 +1,265 @@
+package flipt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// Defaults of the CircuitBreakerConfig.
+const (
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+	defaultHalfOpenProbes   = 1
+)
+
+// CircuitState is the state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all calls through to Flipt.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all calls without calling Flipt.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe calls through to Flipt.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig configures the circuit breaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls which opens the circuit.
+	// It defaults to 5.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probing Flipt again.
+	// It defaults to 30 seconds.
+	OpenDuration time.Duration
+	// HalfOpenProbes is the number of calls let through while the circuit is half-open,
+	// all of which must succeed to close it. It defaults to 1.
+	HalfOpenProbes int
+	// OnStateChange is called on every transition of the circuit.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker wraps the calls to Flipt in a circuit breaker.
+//
+// Calls fail when Flipt is unavailable or errors; failures caused by the flag or the
+// evaluation context and calls abandoned by the caller don't count. Once the
+// circuit opens calls fail immediately with a PROVIDER_NOT_READY resolution error
+// until the open duration has passed, after which probe calls decide whether it closes again.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(p *Provider) {
+		if config.FailureThreshold <= 0 {
+			config.FailureThreshold = defaultFailureThreshold
+		}
+
+		if config.OpenDuration <= 0 {
+			config.OpenDuration = defaultOpenDuration
+		}
+
+		if config.HalfOpenProbes <= 0 {
+			config.HalfOpenProbes = defaultHalfOpenProbes
+		}
+
+		p.breaker = &circuitBreaker{config: config, now: time.Now}
+	}
+}
+
+// CircuitState returns the state of the circuit breaker.
+// It is always closed when no circuit breaker is configured.
+func (p *Provider) CircuitState() CircuitState {
+	if p.breaker == nil {
+		return CircuitClosed
+	}
+
+	p.breaker.mu.Lock()
+	defer p.breaker.mu.Unlock()
+
+	return p.breaker.state
+}
+
+// circuitBreaker is a Service failing calls without delegating to the wrapped
+// Service while its circuit is open.
+type circuitBreaker struct {
+	wrapper
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+// allow reports whether a call may go through, counting it as a probe when half-open.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+
+	var transitions [][2]CircuitState
+
+	defer func() {
+		b.mu.Unlock()
+		b.notify(transitions)
+	}()
+
+	if b.state == CircuitOpen {
+		if b.now().Sub(b.openedAt) < b.config.OpenDuration {
+			return false
+		}
+
+		transitions = append(transitions, b.transition(CircuitHalfOpen))
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.config.HalfOpenProbes {
+			return false
+		}
+
+		b.probes++
+	}
+
+	return true
+}
+
+// record records the outcome of a call which was let through.
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	failed := err != nil && transient(err)
+
+	b.mu.Lock()
+
+	var transitions [][2]CircuitState
+
+	defer func() {
+		b.mu.Unlock()
+		b.notify(transitions)
+	}()
+
+	// calls abandoned by the caller say nothing about Flipt, give their probe back.
+	if err != nil && ctx.Err() != nil {
+		if b.state == CircuitHalfOpen && b.probes > 0 {
+			b.probes--
+		}
+
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			transitions = append(transitions, b.transition(CircuitOpen))
+		}
+	case CircuitHalfOpen:
+		if failed {
+			transitions = append(transitions, b.transition(CircuitOpen))
+
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.config.HalfOpenProbes {
+			transitions = append(transitions, b.transition(CircuitClosed))
+		}
+	}
+}
+
+// transition moves the circuit into the state to and resets its counters.
+// It must be called with the lock held.
+func (b *circuitBreaker) transition(to CircuitState) [2]CircuitState {
+	from := b.state
+
+	b.state = to
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+
+	if to == CircuitOpen {
+		b.openedAt = b.now()
+	}
+
+	return [2]CircuitState{from, to}
+}
+
+func (b *circuitBreaker) notify(transitions [][2]CircuitState) {
+	if b.config.OnStateChange == nil {
+		return
+	}
+
+	for _, t := range transitions {
+		b.config.OnStateChange(t[0], t[1])
+	}
+}
+
+// guard calls fn through the circuit breaker b.
+func guard[T any](ctx context.Context, b *circuitBreaker, fn func() (T, error)) (T, error) {
+	if !b.allow() {
+		var zero T
+
+		return zero, of.NewProviderNotReadyResolutionError("circuit breaker is open")
+	}
+
+	v, err := fn()
+	b.record(ctx, err)
+
+	return v, err
+}
+
+func (b *circuitBreaker) GetNamespace(ctx context.Context, namespaceKey string) (*flipt.Namespace, error) {
+	return guard(ctx, b, func() (*flipt.Namespace, error) {
+		return b.wrapper.GetNamespace(ctx, namespaceKey)
+	})
+}
+
+func (b *circuitBreaker) GetFlag(ctx context.Context, namespaceKey, flagKey string) (*flipt.Flag, error) {
+	return guard(ctx, b, func() (*flipt.Flag, error) {
+		return b.Service.GetFlag(ctx, namespaceKey, flagKey)
+	})
+}
+
+func (b *circuitBreaker) ListFlags(ctx context.Context, namespaceKey string) ([]*flipt.Flag, error) {
+	return guard(ctx, b, func() ([]*flipt.Flag, error) {
+		return b.wrapper.ListFlags(ctx, namespaceKey)
+	})
+}
+
+func (b *circuitBreaker) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	return guard(ctx, b, func() (*evaluation.VariantEvaluationResponse, error) {
+		return b.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+	})
+}
+
+func (b *circuitBreaker) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	return guard(ctx, b, func() (*evaluation.BooleanEvaluationResponse, error) {
+		return b.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+	})
+}
+
+func (b *circuitBreaker) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	return guard(ctx, b, func() (*evaluation.BatchEvaluationResponse, error) {
+		return b.wrapper.Batch(ctx, namespaceKey, flagKeys, evalCtx)
+	})
+}
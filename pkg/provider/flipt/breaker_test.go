@@ -0,0 +1,104 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		evalCtx     = map[string]interface{}{of.TargetingKey: "entity"}
+		transitions [][2]CircuitState
+	)
+
+	mockSvc := newMockService(t)
+	// the second failure opens the circuit, later calls fail without reaching the service.
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(nil, of.NewProviderNotReadyResolutionError("unavailable")).Twice()
+	// flag errors don't count as failures.
+	mockSvc.On("Evaluate", mock.Anything, "default", "missing-flag", evalCtx).Return(nil, of.NewFlagNotFoundResolutionError("not found")).Times(3)
+
+	p := NewProvider(WithService(mockSvc), WithCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		OnStateChange: func(from, to CircuitState) {
+			transitions = append(transitions, [2]CircuitState{from, to})
+		},
+	}))
+
+	now := time.Now()
+	p.breaker.now = func() time.Time { return now }
+
+	p.StringEvaluation(ctx, "missing-flag", "default", evalCtx)
+	p.StringEvaluation(ctx, "missing-flag", "default", evalCtx)
+	p.StringEvaluation(ctx, "missing-flag", "default", evalCtx)
+	assert.Equal(t, CircuitClosed, p.CircuitState())
+
+	p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, CircuitClosed, p.CircuitState())
+
+	p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, CircuitOpen, p.CircuitState())
+
+	detail := p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "default", detail.Value)
+	assert.Equal(t, of.NewProviderNotReadyResolutionError("circuit breaker is open"), detail.ResolutionError)
+
+	// after the open duration a probe is let through, which closes the circuit on success.
+	now = now.Add(time.Minute)
+
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "abc",
+	}, nil).Once()
+
+	detail = p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "abc", detail.Value)
+	assert.Equal(t, CircuitClosed, p.CircuitState())
+
+	assert.Equal(t, [][2]CircuitState{
+		{CircuitClosed, CircuitOpen},
+		{CircuitOpen, CircuitHalfOpen},
+		{CircuitHalfOpen, CircuitClosed},
+	}, transitions)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	b := &circuitBreaker{
+		config: CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute, HalfOpenProbes: 1},
+		now:    time.Now,
+	}
+
+	unavailable := of.NewProviderNotReadyResolutionError("unavailable")
+
+	assert.True(t, b.allow())
+	b.record(context.Background(), unavailable)
+	assert.Equal(t, CircuitOpen, b.state)
+	assert.False(t, b.allow())
+
+	b.openedAt = b.openedAt.Add(-time.Minute)
+
+	// only a single probe is let through while half-open.
+	assert.True(t, b.allow())
+	assert.Equal(t, CircuitHalfOpen, b.state)
+	assert.False(t, b.allow())
+
+	// probes abandoned by the caller are given back.
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.record(canceled, context.Canceled)
+	assert.Equal(t, CircuitHalfOpen, b.state)
+
+	assert.True(t, b.allow())
+	b.record(context.Background(), unavailable)
+	assert.Equal(t, CircuitOpen, b.state)
+
+	assert.Equal(t, CircuitClosed, NewProvider(WithService(newMockService(t))).CircuitState())
+}
@@ -0,0 +1,277 @@
+package flipt
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/proto"
+)
+
+// CacheKey identifies a cached evaluation result.
+type CacheKey struct {
+	NamespaceKey string
+	FlagKey      string
+	// ContextHash is a stable hash of the evaluation context.
+	ContextHash string
+}
+
+// Cache is a store for evaluation results.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if any.
+	Get(key CacheKey) (interface{}, bool)
+	// Set stores value for key.
+	Set(key CacheKey, value interface{})
+	// Invalidate removes all values stored for the given flag key.
+	Invalidate(flagKey string)
+	// Purge removes all values.
+	Purge()
+}
+
+// CacheStats contains the counters of the evaluation cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// WithCache enables caching of evaluation results in the given cache.
+// Use NewLRUCache for a bounded in-memory cache. Results served from the cache
+// are reported with reason CACHED and without the request ID, timestamp and
+// duration of the request which evaluated them.
+func WithCache(cache Cache) Option {
+	return func(p *Provider) {
+		p.cache = &cachedService{cache: cache}
+	}
+}
+
+// Invalidate removes the cached evaluation results of the given flag.
+func (p *Provider) Invalidate(flagKey string) {
+	if p.cache != nil {
+		p.cache.cache.Invalidate(flagKey)
+	}
+}
+
+// Purge removes all cached evaluation results.
+func (p *Provider) Purge() {
+	if p.cache != nil {
+		p.cache.cache.Purge()
+	}
+}
+
+// CacheStats returns the hit and miss counters of the evaluation cache.
+func (p *Provider) CacheStats() CacheStats {
+	if p.cache == nil {
+		return CacheStats{}
+	}
+
+	return CacheStats{
+		Hits:   p.cache.hits.Load(),
+		Misses: p.cache.misses.Load(),
+	}
+}
+
+// cachedService serves evaluations from a cache before delegating to the wrapped
+// Service. Responses served from the cache are marked as CACHED on the context.
+// The cache holds copies of responses, as wrapping services may change them.
+type cachedService struct {
+	wrapper
+	cache  Cache
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *cachedService) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	key, ok := newCacheKey(namespaceKey, flagKey, evalCtx)
+	if ok {
+		if v, found := c.cache.Get(key); found {
+			if resp, ok := v.(*evaluation.VariantEvaluationResponse); ok {
+				c.hits.Add(1)
+				markServed(ctx, of.CachedReason)
+
+				return proto.Clone(resp).(*evaluation.VariantEvaluationResponse), nil
+			}
+		}
+
+		c.misses.Add(1)
+	}
+
+	resp, err := c.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+	if err == nil && ok && servedReason(ctx) == "" {
+		c.cache.Set(key, proto.Clone(resp))
+	}
+
+	return resp, err
+}
+
+func (c *cachedService) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	key, ok := newCacheKey(namespaceKey, flagKey, evalCtx)
+	if ok {
+		if v, found := c.cache.Get(key); found {
+			if resp, ok := v.(*evaluation.BooleanEvaluationResponse); ok {
+				c.hits.Add(1)
+				markServed(ctx, of.CachedReason)
+
+				return proto.Clone(resp).(*evaluation.BooleanEvaluationResponse), nil
+			}
+		}
+
+		c.misses.Add(1)
+	}
+
+	resp, err := c.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+	if err == nil && ok && servedReason(ctx) == "" {
+		c.cache.Set(key, proto.Clone(resp))
+	}
+
+	return resp, err
+}
+
+// newCacheKey returns the cache key for an evaluation. It reports false when
+// the evaluation context cannot be hashed, in which case the cache is bypassed.
+func newCacheKey(namespaceKey, flagKey string, evalCtx map[string]interface{}) (CacheKey, bool) {
+	hash, err := hashContext(evalCtx)
+	if err != nil {
+		return CacheKey{}, false
+	}
+
+	return CacheKey{
+		NamespaceKey: namespaceKey,
+		FlagKey:      flagKey,
+		ContextHash:  hash,
+	}, true
+}
+
+// requestIDAttribute is the evaluation context attribute carrying the ID of
+// the request to Flipt, which doesn't affect the evaluation.
+const requestIDAttribute = "requestID"
+
+// hashContext returns a hash of the evaluation context which does not depend
+// on the iteration order of the map. Values are hashed along with their type,
+// so that for example 1 and "1" don't collide. The request ID is left out.
+func hashContext(evalCtx map[string]interface{}) (string, error) {
+	typed := make(map[string][2]interface{}, len(evalCtx))
+	for k, v := range evalCtx {
+		if k == requestIDAttribute {
+			continue
+		}
+
+		typed[k] = [2]interface{}{fmt.Sprintf("%T", v), v}
+	}
+
+	// encoding/json marshals map keys in sorted order.
+	b, err := json.Marshal(typed)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NewLRUCache returns an in-memory Cache holding at most maxEntries values,
+// evicting the least recently used value first. Values expire after ttl.
+// A maxEntries or ttl of zero disables the respective bound.
+func NewLRUCache(maxEntries int, ttl time.Duration) Cache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    map[CacheKey]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+type lruEntry struct {
+	key       CacheKey
+	value     interface{}
+	expiresAt time.Time
+}
+
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[CacheKey]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+func (c *lruCache) Get(key CacheKey) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.remove(elem)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.value, true
+}
+
+func (c *lruCache) Set(key CacheKey, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *lruCache) Invalidate(flagKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if key.FlagKey == flagKey {
+			c.remove(elem)
+		}
+	}
+}
+
+func (c *lruCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[CacheKey]*list.Element{}
+	c.order.Init()
+}
+
+func (c *lruCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,232 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestLRUCache(t *testing.T) {
+	var (
+		now   = time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+		cache = NewLRUCache(2, time.Minute).(*lruCache)
+		a     = CacheKey{NamespaceKey: "default", FlagKey: "a"}
+		b     = CacheKey{NamespaceKey: "default", FlagKey: "b"}
+		c     = CacheKey{NamespaceKey: "default", FlagKey: "c"}
+	)
+
+	cache.now = func() time.Time { return now }
+
+	cache.Set(a, 1)
+	cache.Set(b, 2)
+
+	// touch a so that b becomes the least recently used entry.
+	v, ok := cache.Get(a)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	cache.Set(c, 3)
+
+	_, ok = cache.Get(b)
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	v, ok = cache.Get(c)
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	now = now.Add(time.Minute)
+
+	_, ok = cache.Get(a)
+	assert.False(t, ok, "entry should expire after ttl")
+
+	cache.Set(a, 1)
+	cache.Invalidate("a")
+
+	_, ok = cache.Get(a)
+	assert.False(t, ok, "invalidated entry should be removed")
+
+	cache.Set(a, 1)
+	cache.Purge()
+
+	_, ok = cache.Get(a)
+	assert.False(t, ok, "purged entry should be removed")
+	assert.Equal(t, 0, cache.order.Len())
+}
+
+func TestHashContext(t *testing.T) {
+	h1, err := hashContext(map[string]interface{}{"targetingKey": "foo", "a": 1, "b": "two"})
+	assert.NoError(t, err)
+
+	h2, err := hashContext(map[string]interface{}{"b": "two", "a": 1, "targetingKey": "foo"})
+	assert.NoError(t, err)
+
+	h3, err := hashContext(map[string]interface{}{"targetingKey": "bar", "a": 1, "b": "two"})
+	assert.NoError(t, err)
+
+	h4, err := hashContext(map[string]interface{}{"targetingKey": "foo", "a": "1", "b": "two"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, h1, h2)
+	assert.NotEqual(t, h1, h3)
+	assert.NotEqual(t, h1, h4, "values of different types should not collide")
+
+	h5, err := hashContext(map[string]interface{}{"targetingKey": "foo", "a": 1, "b": "two", "requestID": "1234"})
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h5, "request ids should not be part of the key")
+
+	_, err = hashContext(map[string]interface{}{"fn": func() {}})
+	assert.Error(t, err)
+}
+
+func TestCachedEvaluation(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "foo"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		VariantKey: "abc",
+	}, nil).Twice()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled: true,
+	}, nil).Once()
+
+	p := NewProvider(WithService(mockSvc), WithCache(NewLRUCache(10, time.Minute)))
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "abc", p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx).Value)
+		assert.True(t, p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx).Value)
+	}
+
+	assert.Equal(t, CacheStats{Hits: 4, Misses: 2}, p.CacheStats())
+
+	p.Invalidate("string-flag")
+
+	assert.Equal(t, "abc", p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx).Value)
+	assert.Equal(t, CacheStats{Hits: 4, Misses: 3}, p.CacheStats())
+}
+
+func TestCachedService(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "foo"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		VariantKey: "abc",
+	}, nil).Once()
+
+	svc := &cachedService{wrapper: wrapper{mockSvc}, cache: NewLRUCache(10, time.Minute)}
+
+	ctx := withServed(context.Background())
+	resp, err := svc.Evaluate(ctx, "default", "string-flag", evalCtx)
+	assert.NoError(t, err)
+	assert.Empty(t, servedReason(ctx))
+
+	resp.VariantKey = "changed"
+
+	ctx = withServed(context.Background())
+	resp, err = svc.Evaluate(ctx, "default", "string-flag", evalCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", resp.VariantKey, "cached responses should not be changed by callers")
+	assert.Equal(t, of.CachedReason, servedReason(ctx))
+
+	resp.VariantKey = "changed"
+
+	resp, err = svc.Evaluate(context.Background(), "default", "string-flag", evalCtx)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", resp.VariantKey)
+}
+
+func TestCachedService_Stale(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "foo"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled: true,
+	}, nil).Once()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(nil, of.NewProviderNotReadyResolutionError("unavailable")).Once()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled: false,
+	}, nil).Once()
+
+	fallback := &fallbackService{wrapper: wrapper{mockSvc}, store: NewLRUCache(10, time.Minute)}
+	_, err := fallback.Boolean(context.Background(), "default", "boolean-flag", evalCtx)
+	assert.NoError(t, err)
+
+	svc := &cachedService{wrapper: wrapper{fallback}, cache: NewLRUCache(10, time.Minute)}
+
+	ctx := withServed(context.Background())
+	resp, err := svc.Boolean(ctx, "default", "boolean-flag", evalCtx)
+	assert.NoError(t, err)
+	assert.True(t, resp.Enabled)
+	assert.Equal(t, StaleReason, servedReason(ctx))
+
+	ctx = withServed(context.Background())
+	resp, err = svc.Boolean(ctx, "default", "boolean-flag", evalCtx)
+	assert.NoError(t, err)
+	assert.False(t, resp.Enabled, "stale responses should not be cached")
+	assert.Empty(t, servedReason(ctx))
+}
+
+func TestCachedEvaluation_Metadata(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "foo"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:                 true,
+		VariantKey:            "abc",
+		Reason:                evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		RequestId:             "first",
+		RequestDurationMillis: 1.5,
+		Timestamp:             timestamppb.New(time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)),
+	}, nil).Once()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled:   true,
+		Reason:    evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		RequestId: "first",
+	}, nil).Once()
+
+	p := NewProvider(WithService(mockSvc), WithCache(NewLRUCache(10, time.Minute)))
+
+	detail := p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+	assert.Equal(t, of.TargetingMatchReason, detail.Reason)
+	assert.Equal(t, "first", detail.FlagMetadata[MetadataRequestID])
+
+	detail = p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+	assert.Equal(t, "abc", detail.Value)
+	assert.Equal(t, "abc", detail.Variant)
+	assert.Equal(t, of.CachedReason, detail.Reason)
+	assert.NoError(t, detail.Error())
+	assert.Equal(t, of.FlagMetadata{
+		MetadataNamespace: "default",
+		MetadataReason:    "MATCH_EVALUATION_REASON",
+	}, detail.FlagMetadata)
+
+	p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx)
+
+	bdetail := p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx)
+	assert.True(t, bdetail.Value)
+	assert.Equal(t, of.CachedReason, bdetail.Reason)
+	assert.NotContains(t, bdetail.FlagMetadata, MetadataRequestID)
+}
+
+func TestCachedEvaluation_Errors(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "foo"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(nil, of.NewGeneralResolutionError("boom")).Twice()
+
+	p := NewProvider(WithService(mockSvc), WithCache(NewLRUCache(10, time.Minute)))
+
+	for i := 0; i < 2; i++ {
+		detail := p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+		assert.Equal(t, "default", detail.Value)
+	}
+
+	assert.Equal(t, CacheStats{Misses: 2}, p.CacheStats())
+}
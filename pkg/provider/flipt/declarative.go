@@ -0,0 +1,92 @@
+package flipt
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// declarativeReloadInterval is the interval at which declarative state files
+// are checked for changes.
+var declarativeReloadInterval = 5 * time.Second
+
+// WithDeclarativePath evaluates flags without a Flipt server from Flipt
+// declarative state files. The path is either a single state file or a
+// directory which is searched for features.yml and *.features.yml files.
+// Changed files are reloaded, and files which can't be read or parsed are
+// reported as a PROVIDER_STALE event while the last valid state keeps being served.
+// The path is resolved on Init, which fails when it doesn't exist or can't be parsed.
+// It has no effect when combined with WithService.
+func WithDeclarativePath(path string) Option {
+	return withLoader(&pathLoader{path: path})
+}
+
+// WithDeclarativeFS evaluates flags without a Flipt server from the Flipt
+// declarative state files (features.yml and *.features.yml) found in fsys,
+// such as an embed.FS. It otherwise behaves like WithDeclarativePath.
+func WithDeclarativeFS(fsys fs.FS) Option {
+	return withLoader(local.NewFSLoader(fsys))
+}
+
+// pathLoader loads declarative state from a state file or a directory, depending
+// on what its path turns out to be on the first load which finds it.
+type pathLoader struct {
+	path string
+
+	mu     sync.Mutex
+	loader local.Loader
+}
+
+func (l *pathLoader) Load(ctx context.Context, namespaceKey string) (*local.Snapshot, error) {
+	l.mu.Lock()
+
+	if l.loader == nil {
+		info, err := os.Stat(l.path)
+		if err != nil {
+			l.mu.Unlock()
+
+			return nil, status.Errorf(codes.Unavailable, "loading features: %v", err)
+		}
+
+		if info.IsDir() {
+			l.loader = local.NewFSLoader(os.DirFS(l.path))
+		} else {
+			l.loader = local.NewFSLoader(os.DirFS(filepath.Dir(l.path)), filepath.Base(l.path))
+		}
+	}
+
+	loader := l.loader
+	l.mu.Unlock()
+
+	return loader.Load(ctx, namespaceKey)
+}
+
+func withLoader(loader local.Loader) Option {
+	return func(p *Provider) {
+		client := local.NewClient(loader,
+			local.WithRefreshInterval(declarativeReloadInterval),
+			local.WithRefreshListener(p.onReload),
+		)
+		p.transportOpts = append(p.transportOpts, transport.WithClient(client))
+	}
+}
+
+// onReload translates the outcome of reloading declarative state into provider events.
+func (p *Provider) onReload(err error) {
+	if err != nil {
+		p.transitionWith(of.StaleState, of.ProviderStale, staleDetails("reloading flag state", err), of.ReadyState)
+
+		return
+	}
+
+	p.transition(of.ReadyState, of.ProviderReady, "flag state reloaded", of.ErrorState, of.StaleState)
+}
@@ -0,0 +1,122 @@
+package flipt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const declarativeFeatures = `flags:
+  - key: string-flag
+    name: String Flag
+    enabled: true
+    variants:
+      - key: blue
+    rules:
+      - segment: everyone
+        distributions:
+          - variant: blue
+            rollout: 100
+segments:
+  - key: everyone
+    name: Everyone
+    match_type: ANY_MATCH_TYPE
+`
+
+func TestWithDeclarativeFS(t *testing.T) {
+	p := NewProvider(WithDeclarativeFS(fstest.MapFS{
+		"features.yml": {Data: []byte(declarativeFeatures)},
+	}))
+	defer p.Shutdown()
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	assert.Equal(t, of.ReadyState, p.Status())
+
+	detail := p.StringEvaluation(context.Background(), "string-flag", "default", map[string]interface{}{of.TargetingKey: "entity"})
+	assert.Equal(t, "blue", detail.Value)
+	assert.Equal(t, of.TargetingMatchReason, detail.Reason)
+
+	detail = p.StringEvaluation(context.Background(), "missing", "default", map[string]interface{}{of.TargetingKey: "entity"})
+	assert.Equal(t, "default", detail.Value)
+	assert.Equal(t, of.FlagNotFoundCode, detail.ResolutionDetail().ErrorCode)
+}
+
+func TestWithDeclarativePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flags.yml")
+
+	require.NoError(t, os.WriteFile(path, []byte("flags: ["), 0o600))
+
+	p := NewProvider(WithDeclarativePath(path))
+	defer p.Shutdown()
+
+	err := p.Init(of.EvaluationContext{})
+	assert.ErrorContains(t, err, "parsing flags.yml")
+	assert.Equal(t, of.ErrorState, p.Status())
+
+	require.NoError(t, os.WriteFile(path, []byte(declarativeFeatures), 0o600))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	assert.Equal(t, of.ReadyState, p.Status())
+}
+
+func TestWithDeclarativePath_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yml")
+
+	// the path is resolved on Init, not when the option is created.
+	p := NewProvider(WithDeclarativePath(path))
+	defer p.Shutdown()
+
+	err := p.Init(of.EvaluationContext{})
+	assert.ErrorContains(t, err, "no such file or directory")
+	assert.Equal(t, of.ErrorState, p.Status())
+
+	require.NoError(t, os.WriteFile(path, []byte(declarativeFeatures), 0o600))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	assert.Equal(t, of.ReadyState, p.Status())
+	assert.Equal(t, "blue", p.StringEvaluation(context.Background(), "string-flag", "default", map[string]interface{}{of.TargetingKey: "entity"}).Value)
+}
+
+func TestWithDeclarativePath_Reload(t *testing.T) {
+	interval := declarativeReloadInterval
+	declarativeReloadInterval = 10 * time.Millisecond
+	t.Cleanup(func() { declarativeReloadInterval = interval })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "features.yml")
+
+	require.NoError(t, os.WriteFile(path, []byte(declarativeFeatures), 0o600))
+
+	p := NewProvider(WithDeclarativePath(dir))
+	defer p.Shutdown()
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	require.NoError(t, os.WriteFile(path, []byte("flags: ["), 0o600))
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderStale, event.EventType)
+	assert.Contains(t, event.Message, "reloading flag state: ")
+	assert.Equal(t, of.StaleState, p.Status())
+
+	// the last valid state keeps being served.
+	assert.Equal(t, "blue", p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx).Value)
+
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(declarativeFeatures, "blue", "green")), 0o600))
+
+	event = nextEvent(t, p)
+	assert.Equal(t, of.ProviderReady, event.EventType)
+	assert.Equal(t, of.ReadyState, p.Status())
+	assert.Equal(t, "green", p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx).Value)
+}
@@ -0,0 +1,342 @@
+package flipt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"google.golang.org/grpc/connectivity"
+)
+
+var _ of.EventHandler = (*Provider)(nil)
+
+// eventBufferSize is the number of events buffered for the OpenFeature SDK
+// before further events are queued and coalesced.
+const eventBufferSize = 16
+
+// WithWatchInterval enables a background watcher which loads the flags of the
+// configured namespace with their rules and rollouts at the given interval and
+// emits a PROVIDER_CONFIGURATION_CHANGED event when any of them are created,
+// updated or deleted, or when their rules, rollouts or segments change.
+// Cached evaluation results of changed flags are invalidated.
+//
+// Each tick loads the namespace, its flags and segments and the rules or rollouts
+// of every flag, that is three requests plus one per flag, so the interval should
+// grow with the number of flags. With local evaluation, the snapshots refreshed
+// by the local client are compared instead, without further requests.
+// It has no effect when the Service set with WithService doesn't implement Snapshotter.
+func WithWatchInterval(interval time.Duration) Option {
+	return func(p *Provider) {
+		p.watchInterval = interval
+	}
+}
+
+// EventChannel returns the channel on which the provider emits events.
+// READY and ERROR events are emitted on connectivity changes after Init,
+// STALE when the flag watcher or local evaluation state fails to refresh, with
+// the namespaces which failed in the staleNamespaces metadata for the latter, and
+// CONFIGURATION_CHANGED when the flag watcher detects changed flags.
+// While the channel is full, further events are queued: configuration changes
+// are merged into one listing all changed flags and only the latest state change
+// is kept. Queued events are discarded on Shutdown.
+func (p *Provider) EventChannel() <-chan of.Event {
+	return p.events
+}
+
+// onTransportState translates connectivity transitions of the transport into provider events.
+func (p *Provider) onTransportState(state connectivity.State) {
+	p.metrics.stateChanged(state)
+
+	switch state {
+	case connectivity.Ready:
+		p.transition(of.ReadyState, of.ProviderReady, "connection to Flipt is ready", of.ErrorState, of.StaleState)
+	case connectivity.TransientFailure:
+		p.transition(of.ErrorState, of.ProviderError, "connection to Flipt failed", of.ReadyState, of.StaleState)
+	}
+}
+
+// transition moves the provider into the state to and emits an event of the
+// given type, if the provider is currently in one of the from states.
+func (p *Provider) transition(to of.State, eventType of.EventType, message string, from ...of.State) {
+	p.transitionWith(to, eventType, of.ProviderEventDetails{Message: message}, from...)
+}
+
+// transitionWith is transition with further details of the event.
+func (p *Provider) transitionWith(to of.State, eventType of.EventType, details of.ProviderEventDetails, from ...of.State) {
+	p.mu.Lock()
+
+	current := p.status
+	if current == to || !containsState(from, current) {
+		p.mu.Unlock()
+
+		return
+	}
+
+	p.status = to
+	p.mu.Unlock()
+
+	p.emit(eventType, details)
+}
+
+// staleDetails describes a failure to refresh local evaluation state, listing
+// the comma-separated namespaces which failed in the staleNamespaces metadata.
+func staleDetails(message string, err error) of.ProviderEventDetails {
+	details := of.ProviderEventDetails{Message: fmt.Sprintf("%s: %v", message, err)}
+
+	if namespaces := local.StaleNamespaces(err); len(namespaces) > 0 {
+		details.EventMetadata = map[string]interface{}{
+			"staleNamespaces": strings.Join(namespaces, ","),
+		}
+	}
+
+	return details
+}
+
+func (p *Provider) emit(eventType of.EventType, details of.ProviderEventDetails) {
+	if details.EventMetadata == nil {
+		details.EventMetadata = map[string]interface{}{}
+	}
+
+	details.EventMetadata["namespace"] = p.config.Namespace
+
+	event := of.Event{
+		ProviderName:         p.Metadata().Name,
+		EventType:            eventType,
+		ProviderEventDetails: details,
+	}
+
+	p.eventsMu.Lock()
+	defer p.eventsMu.Unlock()
+
+	// send directly unless queued events are being forwarded, to keep their order.
+	if p.stopForwarding == nil {
+		select {
+		case p.events <- event:
+			return
+		default:
+		}
+	}
+
+	// never block the caller when the events are consumed slowly, or not at all.
+	p.queued = coalesce(p.queued, event)
+
+	if p.stopForwarding == nil {
+		p.stopForwarding = make(chan struct{})
+		go p.forward(p.stopForwarding)
+	}
+}
+
+// forward sends the queued events until none are left or stop is closed.
+func (p *Provider) forward(stop chan struct{}) {
+	for {
+		p.eventsMu.Lock()
+
+		if p.stopForwarding != stop {
+			p.eventsMu.Unlock()
+
+			return
+		}
+
+		if len(p.queued) == 0 {
+			p.stopForwarding = nil
+			p.eventsMu.Unlock()
+
+			return
+		}
+
+		event := p.queued[0]
+		p.queued = p.queued[1:]
+		p.eventsMu.Unlock()
+
+		select {
+		case p.events <- event:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// stopEvents discards the queued events and stops forwarding them.
+func (p *Provider) stopEvents() {
+	p.eventsMu.Lock()
+	defer p.eventsMu.Unlock()
+
+	if p.stopForwarding != nil {
+		close(p.stopForwarding)
+		p.stopForwarding = nil
+	}
+
+	p.queued = nil
+}
+
+// coalesce queues event, merging the flags of a configuration change into the
+// queued one and replacing a queued state change, as only the latest state matters.
+// The queue thus holds at most one event of each kind.
+func coalesce(queued []of.Event, event of.Event) []of.Event {
+	for i, q := range queued {
+		switch {
+		case event.EventType == of.ProviderConfigChange && q.EventType == of.ProviderConfigChange:
+			queued[i].FlagChanges = mergeKeys(q.FlagChanges, event.FlagChanges)
+
+			return queued
+		case event.EventType != of.ProviderConfigChange && q.EventType != of.ProviderConfigChange:
+			return append(append(queued[:i], queued[i+1:]...), event)
+		}
+	}
+
+	return append(queued, event)
+}
+
+// mergeKeys returns the sorted union of a and b.
+func mergeKeys(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+
+	for _, key := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			merged = append(merged, key)
+		}
+	}
+
+	sort.Strings(merged)
+
+	return merged
+}
+
+// startWatcher starts the flag watcher if one is configured and none is running.
+func (p *Provider) startWatcher() {
+	if _, ok := p.base.(Snapshotter); !ok || p.watchInterval <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopWatcher != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.stopWatcher = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+
+		p.watch(ctx)
+	}()
+}
+
+// watch loads the snapshot of the configured namespace on every tick and emits
+// the keys of the flags which differ from the previous snapshot.
+func (p *Provider) watch(ctx context.Context) {
+	ticker := time.NewTicker(p.watchInterval)
+	defer ticker.Stop()
+
+	var known map[string]string
+
+	for {
+		snap, err := p.svc.Snapshot(ctx, p.config.Namespace)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			p.transition(of.StaleState, of.ProviderStale, fmt.Sprintf("watching flags: %v", err), of.ReadyState)
+		default:
+			p.transition(of.ReadyState, of.ProviderReady, "watching flags resumed", of.ErrorState, of.StaleState)
+
+			current := make(map[string]string, len(snap.Flags))
+			for key, flag := range snap.Flags {
+				current[key] = fingerprint(flag, snap.Rules[key], snap.Rollouts[key])
+			}
+
+			if known != nil {
+				if changed := changedFlags(known, current); len(changed) > 0 {
+					for _, key := range changed {
+						p.Invalidate(key)
+					}
+
+					p.emit(of.ProviderConfigChange, of.ProviderEventDetails{
+						Message:     "flags changed",
+						FlagChanges: changed,
+					})
+				}
+			}
+
+			known = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fingerprint summarizes the parts of a flag which influence its evaluation,
+// including its rules and rollouts and the segments they match.
+func fingerprint(flag *flipt.Flag, rules []*local.Rule, rollouts []*local.Rollout) string {
+	h := sha256.New()
+
+	fmt.Fprintf(h, "%t|%d", flag.Enabled, flag.Type)
+
+	if flag.UpdatedAt != nil {
+		fmt.Fprintf(h, "|%d.%d", flag.UpdatedAt.Seconds, flag.UpdatedAt.Nanos)
+	}
+
+	for _, v := range flag.Variants {
+		fmt.Fprintf(h, "|%q=%q", v.Key, v.Attachment)
+	}
+
+	// rules and rollouts are plain structs, which always encode successfully.
+	_ = json.NewEncoder(h).Encode(rules)
+	_ = json.NewEncoder(h).Encode(rollouts)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changedFlags returns the sorted keys of flags which were added, removed or modified.
+func changedFlags(previous, current map[string]string) []string {
+	var changed []string
+
+	for key, fp := range current {
+		if prev, ok := previous[key]; !ok || prev != fp {
+			changed = append(changed, key)
+		}
+	}
+
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+func containsState(states []of.State, state of.State) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+package flipt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func nextEvent(t *testing.T, p *Provider) of.Event {
+	t.Helper()
+
+	select {
+	case event := <-p.EventChannel():
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for event")
+	}
+
+	return of.Event{}
+}
+
+func TestEventChannel_Connectivity(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+
+	p := NewProvider(WithService(mockSvc))
+
+	// transitions before initialization are reported by the SDK itself.
+	p.onTransportState(connectivity.TransientFailure)
+	assert.Empty(t, p.EventChannel())
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+
+	p.onTransportState(connectivity.TransientFailure)
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderError, event.EventType)
+	assert.Equal(t, "flipt-provider", event.ProviderName)
+	assert.Equal(t, of.ErrorState, p.Status())
+
+	// intermediate states are not reported.
+	p.onTransportState(connectivity.Connecting)
+	assert.Empty(t, p.EventChannel())
+
+	p.onTransportState(connectivity.Ready)
+
+	event = nextEvent(t, p)
+	assert.Equal(t, of.ProviderReady, event.EventType)
+	assert.Equal(t, of.ReadyState, p.Status())
+}
+
+func snapshot(flags ...*flipt.Flag) *local.Snapshot {
+	snap := &local.Snapshot{
+		Flags:    map[string]*flipt.Flag{},
+		Rules:    map[string][]*local.Rule{},
+		Rollouts: map[string][]*local.Rollout{},
+	}
+
+	for _, flag := range flags {
+		snap.Flags[flag.Key] = flag
+	}
+
+	return snap
+}
+
+func TestEventChannel_FlagChanges(t *testing.T) {
+	updated := timestamppb.New(time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "flipt").Return(&flipt.Namespace{Key: "flipt"}, nil)
+	mockSvc.On("Snapshot", mock.Anything, "flipt").Return(snapshot(
+		&flipt.Flag{Key: "unchanged", Enabled: true},
+		&flipt.Flag{Key: "updated", Enabled: true},
+		&flipt.Flag{Key: "deleted", Enabled: true},
+	), nil).Once()
+	mockSvc.On("Snapshot", mock.Anything, "flipt").Return(snapshot(
+		&flipt.Flag{Key: "unchanged", Enabled: true},
+		&flipt.Flag{Key: "updated", Enabled: true, UpdatedAt: updated},
+		&flipt.Flag{Key: "created", Enabled: false},
+	), nil)
+
+	p := NewProvider(WithService(mockSvc), ForNamespace("flipt"), WithWatchInterval(10*time.Millisecond))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	defer p.Shutdown()
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderConfigChange, event.EventType)
+	assert.Equal(t, []string{"created", "deleted", "updated"}, event.FlagChanges)
+	assert.Equal(t, "flipt", event.EventMetadata["namespace"])
+}
+
+func TestEventChannel_RuleChanges(t *testing.T) {
+	flags := []*flipt.Flag{
+		{Key: "variant", Enabled: true, Type: flipt.FlagType_VARIANT_FLAG_TYPE},
+		{Key: "boolean", Enabled: true, Type: flipt.FlagType_BOOLEAN_FLAG_TYPE},
+	}
+
+	segment := &local.Segment{
+		Key:         "beta",
+		MatchType:   flipt.MatchType_ALL_MATCH_TYPE,
+		Constraints: []*local.Constraint{{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Property: "plan", Operator: "eq", Value: "pro"}},
+	}
+
+	before := snapshot(flags...)
+	before.Rules["variant"] = []*local.Rule{{Rank: 1, Segments: []*local.Segment{segment}, Distributions: []*local.Distribution{{Rollout: 100, VariantKey: "a"}}}}
+	before.Rollouts["boolean"] = []*local.Rollout{{Rank: 1, Threshold: &local.Threshold{Percentage: 50, Value: true}}}
+
+	// only the distribution of the rule of the variant flag changes.
+	after := snapshot(flags...)
+	after.Rules["variant"] = []*local.Rule{{Rank: 1, Segments: []*local.Segment{segment}, Distributions: []*local.Distribution{{Rollout: 100, VariantKey: "b"}}}}
+	after.Rollouts["boolean"] = before.Rollouts["boolean"]
+
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+	mockSvc.On("Snapshot", mock.Anything, "default").Return(before, nil).Once()
+	mockSvc.On("Snapshot", mock.Anything, "default").Return(after, nil)
+
+	p := NewProvider(WithService(mockSvc), WithWatchInterval(10*time.Millisecond))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	defer p.Shutdown()
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderConfigChange, event.EventType)
+	assert.Equal(t, []string{"variant"}, event.FlagChanges)
+}
+
+func TestEventChannel_Stale(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+	mockSvc.On("Snapshot", mock.Anything, "default").Return(nil, of.NewGeneralResolutionError("boom")).Once()
+	mockSvc.On("Snapshot", mock.Anything, "default").Return(snapshot(), nil)
+
+	p := NewProvider(WithService(mockSvc), WithWatchInterval(10*time.Millisecond))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	defer p.Shutdown()
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderStale, event.EventType)
+	assert.Equal(t, "watching flags: GENERAL: boom", event.Message)
+
+	event = nextEvent(t, p)
+	assert.Equal(t, of.ProviderReady, event.EventType)
+	assert.Equal(t, of.ReadyState, p.Status())
+}
+
+func TestEventChannel_Refresh(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+
+	p := NewProvider(WithService(mockSvc))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+
+	p.onRefresh(errors.New("connection refused"))
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderStale, event.EventType)
+	assert.Equal(t, "refreshing flag state: connection refused", event.Message)
+	assert.Equal(t, of.StaleState, p.Status())
+
+	// repeated failures are reported once.
+	p.onRefresh(errors.New("connection refused"))
+	assert.Empty(t, p.EventChannel())
+
+	p.onRefresh(nil)
+
+	event = nextEvent(t, p)
+	assert.Equal(t, of.ProviderReady, event.EventType)
+	assert.Equal(t, of.ReadyState, p.Status())
+}
+
+func TestEventChannel_StaleNamespaces(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+
+	p := NewProvider(WithService(mockSvc))
+
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+
+	failed := errors.New("connection refused")
+	p.onRefresh(errors.Join(
+		&local.NamespaceError{Namespace: "default", Err: failed},
+		&local.NamespaceError{Namespace: "other", Err: failed},
+	))
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderStale, event.EventType)
+	assert.Equal(t, "refreshing flag state: namespace \"default\": connection refused\nnamespace \"other\": connection refused", event.Message)
+	assert.Equal(t, "default,other", event.EventMetadata["staleNamespaces"])
+}
+
+func TestEventChannel_Coalesced(t *testing.T) {
+	p := NewProvider(WithService(newMockService(t)))
+	defer p.Shutdown()
+
+	for i := 0; i < eventBufferSize; i++ {
+		p.emit(of.ProviderConfigChange, of.ProviderEventDetails{FlagChanges: []string{"flag"}})
+	}
+
+	// the channel is full, further events are queued without blocking.
+	p.emit(of.ProviderConfigChange, of.ProviderEventDetails{FlagChanges: []string{"b", "a"}})
+	p.emit(of.ProviderError, of.ProviderEventDetails{Message: "failed"})
+	p.emit(of.ProviderConfigChange, of.ProviderEventDetails{FlagChanges: []string{"c", "a"}})
+	p.emit(of.ProviderReady, of.ProviderEventDetails{Message: "ready"})
+
+	for i := 0; i < eventBufferSize; i++ {
+		assert.Equal(t, []string{"flag"}, nextEvent(t, p).FlagChanges)
+	}
+
+	event := nextEvent(t, p)
+	assert.Equal(t, of.ProviderConfigChange, event.EventType)
+	assert.Equal(t, []string{"a", "b", "c"}, event.FlagChanges)
+
+	event = nextEvent(t, p)
+	assert.Equal(t, of.ProviderReady, event.EventType)
+	assert.Equal(t, "ready", event.Message)
+
+	assert.Eventually(t, func() bool {
+		p.eventsMu.Lock()
+		defer p.eventsMu.Unlock()
+
+		return p.stopForwarding == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, p.EventChannel())
+}
@@ -0,0 +1,113 @@
+package flipt
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/proto"
+)
+
+// StaleReason is the reason of resolutions served from the last known good
+// evaluation of a flag because Flipt could not be reached.
+const StaleReason of.Reason = "STALE"
+
+// WithStaleFallback remembers the last successful evaluation of each flag,
+// namespace and evaluation context, and serves it with reason STALE when a
+// later evaluation fails because Flipt is unavailable or errors, for up to maxAge
+// after it succeeded. At most maxEntries evaluations are remembered, evicting
+// the least recently used first; zero removes the bound.
+func WithStaleFallback(maxAge time.Duration, maxEntries int) Option {
+	return func(p *Provider) {
+		p.fallback = &fallbackService{store: NewLRUCache(maxEntries, maxAge)}
+	}
+}
+
+// StaleServes returns the number of resolutions served with reason STALE.
+func (p *Provider) StaleServes() uint64 {
+	if p.fallback == nil {
+		return 0
+	}
+
+	return p.fallback.serves.Load()
+}
+
+// fallbackService remembers the successful evaluations of the wrapped Service and
+// returns copies of them, marked as STALE on the context, when an evaluation
+// fails for reasons other than the flag or the evaluation context.
+type fallbackService struct {
+	wrapper
+	store  Cache
+	serves atomic.Uint64
+}
+
+func (s *fallbackService) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	resp, err := s.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+
+	key, ok := newCacheKey(namespaceKey, flagKey, evalCtx)
+	if !ok {
+		return resp, err
+	}
+
+	if err == nil {
+		s.store.Set(key, proto.Clone(resp))
+
+		return resp, nil
+	}
+
+	if transient(err) {
+		if v, found := s.store.Get(key); found {
+			if stale, ok := v.(*evaluation.VariantEvaluationResponse); ok {
+				s.serves.Add(1)
+				markServed(ctx, StaleReason)
+
+				return proto.Clone(stale).(*evaluation.VariantEvaluationResponse), nil
+			}
+		}
+	}
+
+	return nil, err
+}
+
+func (s *fallbackService) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	resp, err := s.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+
+	key, ok := newCacheKey(namespaceKey, flagKey, evalCtx)
+	if !ok {
+		return resp, err
+	}
+
+	if err == nil {
+		s.store.Set(key, proto.Clone(resp))
+
+		return resp, nil
+	}
+
+	if transient(err) {
+		if v, found := s.store.Get(key); found {
+			if stale, ok := v.(*evaluation.BooleanEvaluationResponse); ok {
+				s.serves.Add(1)
+				markServed(ctx, StaleReason)
+
+				return proto.Clone(stale).(*evaluation.BooleanEvaluationResponse), nil
+			}
+		}
+	}
+
+	return nil, err
+}
+
+// transient reports whether an evaluation failed because of Flipt or the
+// connection to it, rather than because of the flag or the evaluation context.
+func transient(err error) bool {
+	rerr := toResolutionError(err)
+
+	switch (of.ProviderResolutionDetail{ResolutionError: rerr}).ResolutionDetail().ErrorCode {
+	case of.ProviderNotReadyCode, of.GeneralCode:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestStaleFallback(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		evalCtx     = map[string]interface{}{of.TargetingKey: "entity"}
+		unavailable = of.NewProviderNotReadyResolutionError("unavailable")
+	)
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "abc",
+	}, nil).Once()
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(nil, unavailable)
+	mockSvc.On("Evaluate", mock.Anything, "default", "unknown-flag", evalCtx).Return(nil, unavailable)
+	mockSvc.On("Evaluate", mock.Anything, "default", "missing-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "abc",
+	}, nil).Once()
+	mockSvc.On("Evaluate", mock.Anything, "default", "missing-flag", evalCtx).Return(nil, of.NewFlagNotFoundResolutionError("not found"))
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled: true,
+		Reason:  evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+	}, nil).Once()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(nil, unavailable)
+
+	p := NewProvider(WithService(mockSvc), WithStaleFallback(time.Minute, 10))
+
+	now := time.Now()
+	p.fallback.store.(*lruCache).now = func() time.Time { return now }
+
+	s := p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "abc", s.Value)
+	assert.Equal(t, of.TargetingMatchReason, s.Reason)
+
+	s = p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "abc", s.Value)
+	assert.Equal(t, "abc", s.Variant)
+	assert.Equal(t, StaleReason, s.Reason)
+	assert.Nil(t, s.Error())
+
+	b := p.BooleanEvaluation(ctx, "boolean-flag", false, evalCtx)
+	assert.True(t, b.Value)
+
+	b = p.BooleanEvaluation(ctx, "boolean-flag", false, evalCtx)
+	assert.True(t, b.Value)
+	assert.Equal(t, StaleReason, b.Reason)
+
+	// flags which have never been evaluated successfully fall back to the default value.
+	u := p.StringEvaluation(ctx, "unknown-flag", "default", evalCtx)
+	assert.Equal(t, "default", u.Value)
+	assert.Equal(t, of.ErrorReason, u.Reason)
+
+	// errors caused by the flag are not served from the fallback.
+	p.StringEvaluation(ctx, "missing-flag", "default", evalCtx)
+	m := p.StringEvaluation(ctx, "missing-flag", "default", evalCtx)
+	assert.Equal(t, "default", m.Value)
+	assert.Equal(t, of.ErrorReason, m.Reason)
+
+	assert.Equal(t, uint64(2), p.StaleServes())
+
+	now = now.Add(time.Minute)
+
+	s = p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "default", s.Value)
+	assert.Equal(t, of.ErrorReason, s.Reason)
+	assert.Equal(t, uint64(2), p.StaleServes())
+}
+
+func TestStaleFallbackDisabled(t *testing.T) {
+	assert.Equal(t, uint64(0), NewProvider(WithService(newMockService(t))).StaleServes())
+}
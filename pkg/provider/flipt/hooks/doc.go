@@ -0,0 +1,5 @@
+// Package hooks provides [OpenFeature hooks] for use with the Flipt provider,
+// either registered with the provider using flipt.WithHooks or with the OpenFeature API or client.
+//
+// [OpenFeature hooks]: https://openfeature.dev/specification/sections/hooks
+package hooks
@@ -0,0 +1,194 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/provider/flipt"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
+)
+
+var _ of.Hook = (*LoggingHook)(nil)
+
+// LoggingHook logs the outcome of flag evaluations with a structured logger.
+//
+// Successful evaluations are logged at debug level with the flag key, namespace,
+// request ID, variant, reason, duration and the duration of the request as
+// measured by Flipt. Failed evaluations are logged at error level with the flag
+// key, namespace, request ID, duration, error code and error. As the OpenFeature
+// SDK doesn't report the details of failed evaluations to hooks, their namespace
+// is only known when a namespace resolver is set with WithLoggedNamespace, and
+// their request ID when it's set on the context with transport.ContextWithRequestID.
+//
+// Durations are measured from the Before stage of the hook. Concurrent evaluations
+// of a flag with the same context are told apart by the order they started in.
+type LoggingHook struct {
+	of.UnimplementedHook
+	logger    *slog.Logger
+	namespace flipt.NamespaceResolver
+
+	mu     sync.Mutex
+	starts map[evaluation][]time.Time
+}
+
+// evaluation identifies the evaluations of a flag with a context.
+type evaluation struct {
+	ctx     context.Context
+	flagKey string
+}
+
+// LoggingOption is an option of the LoggingHook.
+type LoggingOption func(*LoggingHook)
+
+// WithLoggedNamespace sets the resolver of the namespace logged for failed
+// evaluations, typically the Namespace method of the provider.
+func WithLoggedNamespace(resolver flipt.NamespaceResolver) LoggingOption {
+	return func(h *LoggingHook) {
+		h.namespace = resolver
+	}
+}
+
+// NewLoggingHook returns a LoggingHook which logs to the given logger,
+// or the default logger when it is nil.
+func NewLoggingHook(logger *slog.Logger, opts ...LoggingOption) *LoggingHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	h := &LoggingHook{logger: logger, starts: map[evaluation][]time.Time{}}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Before records the start of an evaluation.
+func (h *LoggingHook) Before(ctx context.Context, hookContext of.HookContext, _ of.HookHints) (*of.EvaluationContext, error) {
+	if key, ok := evaluationOf(ctx, hookContext); ok {
+		h.mu.Lock()
+		h.starts[key] = append(h.starts[key], time.Now())
+		h.mu.Unlock()
+	}
+
+	return nil, nil
+}
+
+// After logs a successful evaluation.
+func (h *LoggingHook) After(ctx context.Context, hookContext of.HookContext, details of.InterfaceEvaluationDetails, _ of.HookHints) error {
+	attrs := []slog.Attr{
+		slog.String("flag_key", hookContext.FlagKey()),
+		slog.String("variant", details.Variant),
+		slog.String("reason", string(details.Reason)),
+	}
+
+	if duration, ok := h.duration(ctx, hookContext); ok {
+		attrs = append(attrs, duration)
+	}
+
+	if namespace, err := details.FlagMetadata.GetString(flipt.MetadataNamespace); err == nil {
+		attrs = append(attrs, slog.String("namespace", namespace))
+	}
+
+	if requestID, err := details.FlagMetadata.GetString(flipt.MetadataRequestID); err == nil {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	if millis, err := details.FlagMetadata.GetFloat(flipt.MetadataRequestDurationMillis); err == nil {
+		attrs = append(attrs, slog.Duration("server_duration", time.Duration(millis*float64(time.Millisecond))))
+	}
+
+	h.logger.LogAttrs(ctx, slog.LevelDebug, "flag evaluated", attrs...)
+
+	return nil
+}
+
+// Error logs a failed evaluation.
+func (h *LoggingHook) Error(ctx context.Context, hookContext of.HookContext, err error, _ of.HookHints) {
+	attrs := []slog.Attr{
+		slog.String("flag_key", hookContext.FlagKey()),
+		slog.String("reason", string(of.ErrorReason)),
+	}
+
+	if h.namespace != nil {
+		if namespace := h.namespace(ctx, hookContext.FlagKey(), hookContext.EvaluationContext().Attributes()); namespace != "" {
+			attrs = append(attrs, slog.String("namespace", namespace))
+		}
+	}
+
+	if requestID, ok := transport.RequestIDFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
+	if duration, ok := h.duration(ctx, hookContext); ok {
+		attrs = append(attrs, duration)
+	}
+
+	attrs = append(attrs,
+		slog.String("error_code", string(errorCode(err))),
+		slog.String("error", err.Error()),
+	)
+
+	h.logger.LogAttrs(ctx, slog.LevelError, "flag evaluation failed", attrs...)
+}
+
+// Finally forgets the start of an evaluation.
+func (h *LoggingHook) Finally(ctx context.Context, hookContext of.HookContext, _ of.HookHints) {
+	key, ok := evaluationOf(ctx, hookContext)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if starts := h.starts[key]; len(starts) > 1 {
+		h.starts[key] = starts[1:]
+	} else {
+		delete(h.starts, key)
+	}
+}
+
+// duration returns the attribute of the duration of an evaluation, if its start was recorded.
+func (h *LoggingHook) duration(ctx context.Context, hookContext of.HookContext) (slog.Attr, bool) {
+	key, ok := evaluationOf(ctx, hookContext)
+	if !ok {
+		return slog.Attr{}, false
+	}
+
+	h.mu.Lock()
+	starts := h.starts[key]
+	h.mu.Unlock()
+
+	if len(starts) == 0 {
+		return slog.Attr{}, false
+	}
+
+	return slog.Duration("duration", time.Since(starts[0])), true
+}
+
+// evaluationOf returns the evaluation of a hook context, unless its context can't identify it.
+func evaluationOf(ctx context.Context, hookContext of.HookContext) (evaluation, bool) {
+	if !reflect.TypeOf(ctx).Comparable() {
+		return evaluation{}, false
+	}
+
+	return evaluation{ctx: ctx, flagKey: hookContext.FlagKey()}, true
+}
+
+// errorCode returns the OpenFeature error code of an evaluation error, which
+// is GENERAL unless the error wraps a resolution error.
+func errorCode(err error) of.ErrorCode {
+	var rerr of.ResolutionError
+	if !errors.As(err, &rerr) {
+		return of.GeneralCode
+	}
+
+	return of.ProviderResolutionDetail{ResolutionError: rerr}.ResolutionDetail().ErrorCode
+}
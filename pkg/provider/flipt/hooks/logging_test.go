@@ -0,0 +1,140 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt-openfeature-provider/pkg/provider/flipt"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
+)
+
+func TestLoggingHook(t *testing.T) {
+	var buf bytes.Buffer
+
+	hook := NewLoggingHook(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})), WithLoggedNamespace(func(context.Context, string, of.FlattenedContext) string {
+		return "flipt"
+	}))
+
+	hookContext := of.NewHookContext("flag", of.String, "default", of.ClientMetadata{}, of.Metadata{}, of.NewEvaluationContext("entity", nil))
+
+	_, err := hook.Before(context.Background(), hookContext, of.HookHints{})
+	require.NoError(t, err)
+
+	err = hook.After(context.Background(), hookContext, of.InterfaceEvaluationDetails{
+		Value: "blue",
+		EvaluationDetails: of.EvaluationDetails{
+			FlagKey: "flag",
+			ResolutionDetail: of.ResolutionDetail{
+				Variant: "blue",
+				Reason:  of.TargetingMatchReason,
+				FlagMetadata: of.FlagMetadata{
+					flipt.MetadataNamespace:             "flipt",
+					flipt.MetadataRequestID:             "request",
+					flipt.MetadataRequestDurationMillis: 1.5,
+				},
+			},
+		},
+	}, of.HookHints{})
+	require.NoError(t, err)
+
+	hook.Finally(context.Background(), hookContext, of.HookHints{})
+
+	ctx := transport.ContextWithRequestID(context.Background(), "request")
+
+	_, err = hook.Before(ctx, hookContext, of.HookHints{})
+	require.NoError(t, err)
+
+	hook.Error(ctx, hookContext, fmt.Errorf("error code: %w", of.NewFlagNotFoundResolutionError("flag not found")), of.HookHints{})
+	hook.Finally(ctx, hookContext, of.HookHints{})
+
+	assert.Empty(t, hook.starts)
+
+	dec := json.NewDecoder(&buf)
+
+	var after, failed map[string]interface{}
+	require.NoError(t, dec.Decode(&after))
+	require.NoError(t, dec.Decode(&failed))
+
+	for _, record := range []map[string]interface{}{after, failed} {
+		assert.Greater(t, record["duration"], float64(0))
+		delete(record, "duration")
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"level":           "DEBUG",
+		"msg":             "flag evaluated",
+		"flag_key":        "flag",
+		"namespace":       "flipt",
+		"request_id":      "request",
+		"variant":         "blue",
+		"reason":          "TARGETING_MATCH",
+		"server_duration": float64(1500000),
+	}, after)
+
+	assert.Equal(t, map[string]interface{}{
+		"level":      "ERROR",
+		"msg":        "flag evaluation failed",
+		"flag_key":   "flag",
+		"namespace":  "flipt",
+		"request_id": "request",
+		"reason":     "ERROR",
+		"error_code": "FLAG_NOT_FOUND",
+		"error":      "error code: FLAG_NOT_FOUND: flag not found",
+	}, failed)
+}
+
+func TestLoggingHook_Durations(t *testing.T) {
+	hook := NewLoggingHook(nil)
+
+	var (
+		ctx   = context.Background()
+		first = of.NewHookContext("flag", of.Boolean, false, of.ClientMetadata{}, of.Metadata{}, of.NewEvaluationContext("entity", nil))
+		other = of.NewHookContext("other", of.Boolean, false, of.ClientMetadata{}, of.Metadata{}, of.NewEvaluationContext("entity", nil))
+	)
+
+	_, err := hook.Before(ctx, first, of.HookHints{})
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = hook.Before(ctx, other, of.HookHints{})
+	require.NoError(t, err)
+
+	firstDuration, ok := hook.duration(ctx, first)
+	require.True(t, ok)
+
+	otherDuration, ok := hook.duration(ctx, other)
+	require.True(t, ok)
+
+	assert.Greater(t, firstDuration.Value.Duration(), otherDuration.Value.Duration())
+
+	hook.Finally(ctx, first, of.HookHints{})
+	hook.Finally(ctx, other, of.HookHints{})
+
+	_, ok = hook.duration(ctx, first)
+	assert.False(t, ok, "durations of evaluations which didn't start can't be measured")
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, of.TargetingKeyMissingCode, errorCode(fmt.Errorf("before hook: %w", of.NewTargetingKeyMissingResolutionError("targetingKey is missing"))))
+	assert.Equal(t, of.ParseErrorCode, errorCode(fmt.Errorf("error code: %w", of.NewParseErrorResolutionError("invalid: JSON"))))
+	assert.Equal(t, of.GeneralCode, errorCode(errors.New("unexpected: FLAG_NOT_FOUND: flag not found")))
+}
@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"context"
+	"strings"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/provider/flipt"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const eventName = "feature_flag"
+
+// Attributes of feature_flag span events which are not covered by the
+// OpenTelemetry semantic conventions.
+const (
+	ReasonKey      = attribute.Key("feature_flag.reason")
+	ErrorCodeKey   = attribute.Key("feature_flag.error_code")
+	NamespaceKey   = attribute.Key("flipt.namespace")
+	RequestIDKey   = attribute.Key("flipt.request_id")
+	SegmentKeysKey = attribute.Key("flipt.segment_keys")
+)
+
+var _ of.Hook = (*TracingHook)(nil)
+
+// TracingHook records flag evaluations as feature_flag events on the span of
+// the evaluation context, following the OpenTelemetry semantic conventions for
+// feature flags. Events carry the flag key, provider name, variant and reason,
+// as well as the namespace, request ID and matched segments reported by Flipt.
+// Failed evaluations are recorded as errors on the span.
+type TracingHook struct {
+	of.UnimplementedHook
+}
+
+// NewTracingHook returns a new TracingHook.
+func NewTracingHook() *TracingHook {
+	return &TracingHook{}
+}
+
+// After adds a feature_flag event for a successful evaluation.
+func (h *TracingHook) After(ctx context.Context, hookContext of.HookContext, details of.InterfaceEvaluationDetails, _ of.HookHints) error {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.FeatureFlagKey(hookContext.FlagKey()),
+		semconv.FeatureFlagProviderName(hookContext.ProviderMetadata().Name),
+		semconv.FeatureFlagVariant(details.Variant),
+		ReasonKey.String(string(details.Reason)),
+	}
+
+	if namespace, err := details.FlagMetadata.GetString(flipt.MetadataNamespace); err == nil {
+		attrs = append(attrs, NamespaceKey.String(namespace))
+	}
+
+	if requestID, err := details.FlagMetadata.GetString(flipt.MetadataRequestID); err == nil {
+		attrs = append(attrs, RequestIDKey.String(requestID))
+	}
+
+	if segmentKeys, err := details.FlagMetadata.GetString(flipt.MetadataSegmentKeys); err == nil {
+		attrs = append(attrs, SegmentKeysKey.StringSlice(strings.Split(segmentKeys, ",")))
+	}
+
+	span.AddEvent(eventName, trace.WithAttributes(attrs...))
+
+	return nil
+}
+
+// Error records a failed evaluation on the span.
+func (h *TracingHook) Error(ctx context.Context, hookContext of.HookContext, err error, _ of.HookHints) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.RecordError(err, trace.WithAttributes(
+		semconv.FeatureFlagKey(hookContext.FlagKey()),
+		semconv.FeatureFlagProviderName(hookContext.ProviderMetadata().Name),
+		ReasonKey.String(string(of.ErrorReason)),
+		ErrorCodeKey.String(string(errorCode(err))),
+	))
+}
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt-openfeature-provider/pkg/provider/flipt"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+func TestTracingHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+
+	hook := NewTracingHook()
+	hookContext := of.NewHookContext("flag", of.String, "default", of.ClientMetadata{}, of.Metadata{Name: "flipt-provider"}, of.NewEvaluationContext("entity", nil))
+
+	err := hook.After(ctx, hookContext, of.InterfaceEvaluationDetails{
+		Value: "blue",
+		EvaluationDetails: of.EvaluationDetails{
+			FlagKey: "flag",
+			ResolutionDetail: of.ResolutionDetail{
+				Variant: "blue",
+				Reason:  of.TargetingMatchReason,
+				FlagMetadata: of.FlagMetadata{
+					flipt.MetadataNamespace:   "flipt",
+					flipt.MetadataRequestID:   "request",
+					flipt.MetadataSegmentKeys: "premium,beta",
+				},
+			},
+		},
+	}, of.HookHints{})
+	require.NoError(t, err)
+
+	hook.Error(ctx, hookContext, fmt.Errorf("error code: %w", of.NewFlagNotFoundResolutionError("flag not found")), of.HookHints{})
+
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	events := spans[0].Events()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "feature_flag", events[0].Name)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.FeatureFlagKey("flag"),
+		semconv.FeatureFlagProviderName("flipt-provider"),
+		semconv.FeatureFlagVariant("blue"),
+		ReasonKey.String("TARGETING_MATCH"),
+		NamespaceKey.String("flipt"),
+		RequestIDKey.String("request"),
+		SegmentKeysKey.StringSlice([]string{"premium", "beta"}),
+	}, events[0].Attributes)
+
+	assert.Equal(t, "exception", events[1].Name)
+	assert.Subset(t, events[1].Attributes, []attribute.KeyValue{
+		semconv.FeatureFlagKey("flag"),
+		ErrorCodeKey.String("FLAG_NOT_FOUND"),
+		semconv.ExceptionMessage("error code: FLAG_NOT_FOUND: flag not found"),
+	})
+}
+
+func TestTracingHook_NotRecording(t *testing.T) {
+	hook := NewTracingHook()
+	hookContext := of.NewHookContext("flag", of.String, "default", of.ClientMetadata{}, of.Metadata{}, of.NewEvaluationContext("entity", nil))
+
+	assert.NoError(t, hook.After(context.Background(), hookContext, of.InterfaceEvaluationDetails{}, of.HookHints{}))
+	hook.Error(context.Background(), hookContext, errors.New("failed"), of.HookHints{})
+}
@@ -0,0 +1,98 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
+)
+
+var _ of.Hook = (*ValidationHook)(nil)
+
+// ValidationHook validates evaluation contexts before they are sent to Flipt,
+// failing the evaluation with the default value when a context is invalid.
+//
+// A context is valid when it has all required attributes, a targeting key when
+// one is required, and only attributes which the context encoder can encode,
+// the default transport.ContextEncoder unless one is set with WithContextEncoder.
+type ValidationHook struct {
+	of.UnimplementedHook
+	required            []string
+	requireTargetingKey bool
+	encoder             *transport.ContextEncoder
+}
+
+// ValidationOption is an option of the ValidationHook.
+type ValidationOption func(*ValidationHook)
+
+// WithRequiredAttributes requires evaluation contexts to have the given attributes.
+func WithRequiredAttributes(attributes ...string) ValidationOption {
+	return func(h *ValidationHook) {
+		h.required = append(h.required, attributes...)
+	}
+}
+
+// WithRequiredTargetingKey requires evaluation contexts to have a targeting key.
+func WithRequiredTargetingKey() ValidationOption {
+	return func(h *ValidationHook) {
+		h.requireTargetingKey = true
+	}
+}
+
+// WithContextEncoder sets the encoder which attributes are validated with,
+// which should be the one the provider is configured with.
+func WithContextEncoder(encoder *transport.ContextEncoder) ValidationOption {
+	return func(h *ValidationHook) {
+		h.encoder = encoder
+	}
+}
+
+// NewValidationHook returns a new ValidationHook.
+func NewValidationHook(opts ...ValidationOption) *ValidationHook {
+	h := &ValidationHook{}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.encoder == nil {
+		h.encoder = transport.NewContextEncoder()
+	}
+
+	return h
+}
+
+// Before validates the evaluation context.
+func (h *ValidationHook) Before(_ context.Context, hookContext of.HookContext, _ of.HookHints) (*of.EvaluationContext, error) {
+	evalCtx := hookContext.EvaluationContext()
+
+	if h.requireTargetingKey && evalCtx.TargetingKey() == "" {
+		return nil, of.NewTargetingKeyMissingResolutionError("targetingKey is missing")
+	}
+
+	attributes := evalCtx.Attributes()
+
+	for _, attribute := range h.required {
+		if _, ok := attributes[attribute]; !ok {
+			return nil, of.NewInvalidContextResolutionError(fmt.Sprintf("attribute %q is missing", attribute))
+		}
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	// attributes are encoded one at a time, so that the first invalid one is always reported.
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, err := h.encoder.Encode(map[string]interface{}{key: attributes[key]}); err != nil {
+			return nil, of.NewInvalidContextResolutionError(err.Error())
+		}
+	}
+
+	return nil, nil
+}
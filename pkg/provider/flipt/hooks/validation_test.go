@@ -0,0 +1,113 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
+)
+
+func TestValidationHook(t *testing.T) {
+	type ip [4]byte
+
+	tests := []struct {
+		name        string
+		opts        []ValidationOption
+		evalCtx     of.EvaluationContext
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant":  "acme",
+				"age":     42,
+				"premium": true,
+				"created": time.Now(),
+			}),
+		},
+		{
+			name:    "missing targeting key",
+			evalCtx: of.NewEvaluationContext("", map[string]interface{}{"tenant": "acme"}),
+		},
+		{
+			name:        "missing required targeting key",
+			opts:        []ValidationOption{WithRequiredTargetingKey()},
+			evalCtx:     of.NewEvaluationContext("", map[string]interface{}{"tenant": "acme"}),
+			expectedErr: "TARGETING_KEY_MISSING: targetingKey is missing",
+		},
+		{
+			name:        "missing required attribute",
+			evalCtx:     of.NewEvaluationContext("entity", nil),
+			expectedErr: `INVALID_CONTEXT: attribute "tenant" is missing`,
+		},
+		{
+			name: "json encoded types",
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant":  "acme",
+				"roles":   []string{"admin"},
+				"account": map[string]interface{}{"plan": "pro", "seats": 5},
+			}),
+		},
+		{
+			name: "formatted types",
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant": "acme",
+				"ratio":  complex(1, 2),
+			}),
+		},
+		{
+			name: "not json encodable",
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant":  "acme",
+				"account": map[string]interface{}{"updates": make(chan int)},
+			}),
+			expectedErr: `INVALID_CONTEXT: encoding "account": json: unsupported type: chan int`,
+		},
+		{
+			name: "registered encoder",
+			opts: []ValidationOption{WithContextEncoder(transport.NewContextEncoder(
+				transport.WithEncoder(func(v map[string]interface{}) (string, error) {
+					return fmt.Sprint(len(v)), nil
+				}),
+			))},
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant":  "acme",
+				"account": map[string]interface{}{"updates": make(chan int)},
+			}),
+		},
+		{
+			name: "failing registered encoder",
+			opts: []ValidationOption{WithContextEncoder(transport.NewContextEncoder(
+				transport.WithEncoder(func(v ip) (string, error) {
+					return "", errors.New("invalid address")
+				}),
+			))},
+			evalCtx: of.NewEvaluationContext("entity", map[string]interface{}{
+				"tenant":  "acme",
+				"address": ip{},
+			}),
+			expectedErr: `INVALID_CONTEXT: encoding "address": invalid address`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := NewValidationHook(append([]ValidationOption{WithRequiredAttributes("tenant")}, tt.opts...)...)
+			hookContext := of.NewHookContext("flag", of.Boolean, false, of.ClientMetadata{}, of.Metadata{}, tt.evalCtx)
+
+			evalCtx, err := hook.Before(context.Background(), hookContext, of.HookHints{})
+			assert.Nil(t, evalCtx)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package flipt
+
+import (
+	"strings"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// Keys of the flag metadata populated from Flipt evaluation responses.
+// Flag metadata only holds scalar values, so segment keys are joined by commas.
+const (
+	MetadataNamespace             = "namespace"
+	MetadataSegmentKeys           = "segmentKeys"
+	MetadataRequestID             = "requestId"
+	MetadataRequestDurationMillis = "requestDurationMillis"
+	MetadataTimestamp             = "timestamp"
+	MetadataReason                = "fliptReason"
+	// MetadataTimedOut is set to true on evaluations which exceeded their timeout.
+	MetadataTimedOut = "timedOut"
+)
+
+// namespaceMetadata returns the flag metadata of an evaluation without a response from Flipt.
+func namespaceMetadata(namespace string) of.FlagMetadata {
+	return of.FlagMetadata{MetadataNamespace: namespace}
+}
+
+// variantMetadata returns the flag metadata of a variant evaluation.
+func variantMetadata(namespace string, resp *evaluation.VariantEvaluationResponse) of.FlagMetadata {
+	metadata := namespaceMetadata(namespace)
+	metadata[MetadataReason] = resp.Reason.String()
+	metadata[MetadataRequestDurationMillis] = resp.RequestDurationMillis
+
+	if len(resp.SegmentKeys) > 0 {
+		metadata[MetadataSegmentKeys] = strings.Join(resp.SegmentKeys, ",")
+	}
+
+	if resp.RequestId != "" {
+		metadata[MetadataRequestID] = resp.RequestId
+	}
+
+	if resp.Timestamp != nil {
+		metadata[MetadataTimestamp] = resp.Timestamp.AsTime().Format(time.RFC3339Nano)
+	}
+
+	return metadata
+}
+
+// booleanMetadata returns the flag metadata of a boolean evaluation.
+// Boolean evaluation responses don't report the matched segments.
+func booleanMetadata(namespace string, resp *evaluation.BooleanEvaluationResponse) of.FlagMetadata {
+	metadata := namespaceMetadata(namespace)
+	metadata[MetadataReason] = resp.Reason.String()
+	metadata[MetadataRequestDurationMillis] = resp.RequestDurationMillis
+
+	if resp.RequestId != "" {
+		metadata[MetadataRequestID] = resp.RequestId
+	}
+
+	if resp.Timestamp != nil {
+		metadata[MetadataTimestamp] = resp.Timestamp.AsTime().Format(time.RFC3339Nano)
+	}
+
+	return metadata
+}
@@ -0,0 +1,62 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFlagMetadata(t *testing.T) {
+	var (
+		ts      = time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+		evalCtx = map[string]interface{}{of.TargetingKey: "foo"}
+	)
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "flipt", "string-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:                 true,
+		SegmentKeys:           []string{"premium", "beta"},
+		Reason:                evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey:            "abc",
+		RequestId:             "request-id",
+		RequestDurationMillis: 1.5,
+		Timestamp:             timestamppb.New(ts),
+	}, nil)
+	mockSvc.On("Boolean", mock.Anything, "flipt", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{
+		Enabled:               true,
+		Reason:                evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON,
+		RequestId:             "request-id",
+		RequestDurationMillis: 0.5,
+		Timestamp:             timestamppb.New(ts),
+	}, nil)
+	mockSvc.On("Evaluate", mock.Anything, "flipt", "missing", evalCtx).Return(nil, of.NewFlagNotFoundResolutionError("not found"))
+
+	p := NewProvider(WithService(mockSvc), ForNamespace("flipt"))
+
+	assert.Equal(t, of.FlagMetadata{
+		MetadataNamespace:             "flipt",
+		MetadataSegmentKeys:           "premium,beta",
+		MetadataRequestID:             "request-id",
+		MetadataRequestDurationMillis: 1.5,
+		MetadataTimestamp:             "2023-10-01T12:00:00Z",
+		MetadataReason:                "MATCH_EVALUATION_REASON",
+	}, p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx).FlagMetadata)
+
+	assert.Equal(t, of.FlagMetadata{
+		MetadataNamespace:             "flipt",
+		MetadataRequestID:             "request-id",
+		MetadataRequestDurationMillis: 0.5,
+		MetadataTimestamp:             "2023-10-01T12:00:00Z",
+		MetadataReason:                "DEFAULT_EVALUATION_REASON",
+	}, p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx).FlagMetadata)
+
+	assert.Equal(t, of.FlagMetadata{
+		MetadataNamespace: "flipt",
+	}, p.StringEvaluation(context.Background(), "missing", "default", evalCtx).FlagMetadata)
+}
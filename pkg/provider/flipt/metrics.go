@@ -0,0 +1,154 @@
+package flipt
+
+import (
+	"context"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc/connectivity"
+)
+
+const meterName = "go.flipt.io/flipt-openfeature-provider"
+
+// Attributes of the provider metrics.
+const (
+	MetricFlagKey   = attribute.Key("feature_flag.key")
+	MetricVariant   = attribute.Key("feature_flag.variant")
+	MetricReason    = attribute.Key("feature_flag.reason")
+	MetricErrorCode = attribute.Key("feature_flag.error_code")
+	MetricState     = attribute.Key("flipt.connection.state")
+)
+
+// WithMeterProvider enables metrics, which are recorded with meters of the given provider:
+//
+//   - flipt.provider.evaluations counts evaluations by flag key, variant, reason and error code.
+//   - flipt.provider.evaluation.duration records the duration of evaluations in seconds by flag key.
+//   - flipt.provider.requests.active counts the evaluation requests to Flipt in flight.
+//   - flipt.provider.connection.transitions counts the transitions of the connection to Flipt by state.
+//
+// A nil meterProvider stands for the global one.
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(p *Provider) {
+		if meterProvider == nil {
+			meterProvider = otel.GetMeterProvider()
+		}
+
+		p.metrics = newMetrics(meterProvider.Meter(meterName))
+	}
+}
+
+type metrics struct {
+	evaluations metric.Int64Counter
+	duration    metric.Float64Histogram
+	active      metric.Int64UpDownCounter
+	transitions metric.Int64Counter
+}
+
+// newMetrics creates the instruments of the provider metrics. Failures to create an
+// instrument are reported to the OpenTelemetry error handler and the instrument
+// is replaced by a no-op one.
+func newMetrics(meter metric.Meter) *metrics {
+	var (
+		m   = &metrics{}
+		err error
+	)
+
+	m.evaluations, err = meter.Int64Counter("flipt.provider.evaluations",
+		metric.WithDescription("The number of flag evaluations."),
+		metric.WithUnit("{evaluation}"))
+	if err != nil {
+		otel.Handle(err)
+		m.evaluations = noop.Int64Counter{}
+	}
+
+	m.duration, err = meter.Float64Histogram("flipt.provider.evaluation.duration",
+		metric.WithDescription("The duration of flag evaluations."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+		m.duration = noop.Float64Histogram{}
+	}
+
+	m.active, err = meter.Int64UpDownCounter("flipt.provider.requests.active",
+		metric.WithDescription("The number of evaluation requests to Flipt in flight."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+		m.active = noop.Int64UpDownCounter{}
+	}
+
+	m.transitions, err = meter.Int64Counter("flipt.provider.connection.transitions",
+		metric.WithDescription("The number of state transitions of the connection to Flipt."),
+		metric.WithUnit("{transition}"))
+	if err != nil {
+		otel.Handle(err)
+		m.transitions = noop.Int64Counter{}
+	}
+
+	return m
+}
+
+// evaluated records the outcome of an evaluation which started at start.
+// It is a no-op when metrics are disabled.
+func (m *metrics) evaluated(ctx context.Context, flagKey string, start time.Time, detail of.ProviderResolutionDetail) {
+	if m == nil {
+		return
+	}
+
+	d := detail.ResolutionDetail()
+
+	attrs := []attribute.KeyValue{
+		MetricFlagKey.String(flagKey),
+		MetricVariant.String(d.Variant),
+		MetricReason.String(string(d.Reason)),
+	}
+
+	if d.ErrorCode != "" {
+		attrs = append(attrs, MetricErrorCode.String(string(d.ErrorCode)))
+	}
+
+	m.evaluations.Add(ctx, 1, metric.WithAttributes(attrs...))
+	m.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(MetricFlagKey.String(flagKey)))
+}
+
+// stateChanged records a transition of the connection to Flipt.
+// It is a no-op when metrics are disabled.
+func (m *metrics) stateChanged(state connectivity.State) {
+	if m == nil {
+		return
+	}
+
+	m.transitions.Add(context.Background(), 1, metric.WithAttributes(MetricState.String(state.String())))
+}
+
+// meteredService counts the evaluation requests of the wrapped Service in flight.
+type meteredService struct {
+	wrapper
+	metrics *metrics
+}
+
+func (s *meteredService) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	s.metrics.active.Add(ctx, 1)
+	defer s.metrics.active.Add(ctx, -1)
+
+	return s.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+}
+
+func (s *meteredService) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	s.metrics.active.Add(ctx, 1)
+	defer s.metrics.active.Add(ctx, -1)
+
+	return s.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+}
+
+func (s *meteredService) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	s.metrics.active.Add(ctx, 1)
+	defer s.metrics.active.Add(ctx, -1)
+
+	return s.wrapper.Batch(ctx, namespaceKey, flagKeys, evalCtx)
+}
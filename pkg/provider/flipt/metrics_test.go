@@ -0,0 +1,109 @@
+package flipt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "abc",
+	}, nil).Twice()
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", mock.Anything).Return(nil, of.NewFlagNotFoundResolutionError("not found"))
+
+	p := NewProvider(WithService(mockSvc), WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+	p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+	p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx)
+	p.onTransportState(connectivity.TransientFailure)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	evaluations, ok := metrics["flipt.provider.evaluations"].(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	counts := map[attribute.Distinct]int64{}
+	for _, dp := range evaluations.DataPoints {
+		counts[dp.Attributes.Equivalent()] = dp.Value
+	}
+
+	matched := attribute.NewSet(
+		MetricFlagKey.String("string-flag"),
+		MetricVariant.String("abc"),
+		MetricReason.String("TARGETING_MATCH"),
+	)
+	failed := attribute.NewSet(
+		MetricFlagKey.String("boolean-flag"),
+		MetricVariant.String(""),
+		MetricReason.String("ERROR"),
+		MetricErrorCode.String("FLAG_NOT_FOUND"),
+	)
+
+	assert.Equal(t, map[attribute.Distinct]int64{
+		matched.Equivalent(): 2,
+		failed.Equivalent():  1,
+	}, counts)
+
+	duration, ok := metrics["flipt.provider.evaluation.duration"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Len(t, duration.DataPoints, 2)
+
+	active, ok := metrics["flipt.provider.requests.active"].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, active.DataPoints, 1)
+	assert.Equal(t, int64(0), active.DataPoints[0].Value)
+
+	transitions, ok := metrics["flipt.provider.connection.transitions"].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, transitions.DataPoints, 1)
+	assert.Equal(t, int64(1), transitions.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(MetricState.String("TRANSIENT_FAILURE")), transitions.DataPoints[0].Attributes)
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", mock.Anything).Return(nil, errors.New("unavailable"))
+
+	p := NewProvider(WithService(mockSvc))
+
+	detail := p.StringEvaluation(context.Background(), "string-flag", "default", map[string]interface{}{of.TargetingKey: "entity"})
+	assert.Equal(t, "default", detail.Value)
+	assert.Nil(t, p.metrics)
+}
+
+func TestMetricsGlobalMeterProvider(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", mock.Anything).Return(nil, errors.New("unavailable"))
+
+	p := NewProvider(WithService(mockSvc), WithMeterProvider(nil))
+
+	detail := p.StringEvaluation(context.Background(), "string-flag", "default", map[string]interface{}{of.TargetingKey: "entity"})
+	assert.Equal(t, "default", detail.Value)
+	assert.NotNil(t, p.metrics)
+}
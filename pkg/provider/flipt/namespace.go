@@ -0,0 +1,61 @@
+package flipt
+
+import (
+	"context"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+)
+
+// NamespaceResolver resolves the namespace a flag is evaluated in.
+// Returning an empty string falls back to the next source of the namespace.
+type NamespaceResolver func(ctx context.Context, flagKey string, evalCtx of.FlattenedContext) string
+
+type namespaceKey struct{}
+
+// WithNamespaceResolver sets a resolver which determines the namespace of each evaluation.
+//
+// The namespace of an evaluation is, in order of precedence, the one returned by
+// the resolver, the one set on the context with ContextWithNamespace and the
+// namespace of the provider configuration. It is reported in the flag metadata.
+// Initialization and the flag watcher always use the configured namespace.
+func WithNamespaceResolver(resolver NamespaceResolver) Option {
+	return func(p *Provider) {
+		p.namespaceResolver = resolver
+	}
+}
+
+// ContextWithNamespace returns a copy of ctx in which flags are evaluated in the given namespace.
+func ContextWithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromAttribute returns a NamespaceResolver which reads the namespace
+// from the given evaluation context attribute.
+func NamespaceFromAttribute(attribute string) NamespaceResolver {
+	return func(_ context.Context, _ string, evalCtx of.FlattenedContext) string {
+		namespace, _ := evalCtx[attribute].(string)
+
+		return namespace
+	}
+}
+
+// Namespace returns the namespace an evaluation of the flag takes place in.
+// It's a NamespaceResolver, for example for hooks.WithLoggedNamespace.
+func (p *Provider) Namespace(ctx context.Context, flagKey string, evalCtx of.FlattenedContext) string {
+	return p.namespace(ctx, flagKey, evalCtx)
+}
+
+// namespace resolves the namespace an evaluation of the flag takes place in.
+func (p *Provider) namespace(ctx context.Context, flagKey string, evalCtx of.FlattenedContext) string {
+	if p.namespaceResolver != nil {
+		if namespace := p.namespaceResolver(ctx, flagKey, evalCtx); namespace != "" {
+			return namespace
+		}
+	}
+
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok && namespace != "" {
+		return namespace
+	}
+
+	return p.config.Namespace
+}
@@ -0,0 +1,75 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestNamespaceResolution(t *testing.T) {
+	tests := []struct {
+		name              string
+		resolver          NamespaceResolver
+		ctx               context.Context
+		evalCtx           map[string]interface{}
+		expectedNamespace string
+	}{
+		{
+			name:              "configured",
+			ctx:               context.Background(),
+			expectedNamespace: "flipt",
+		},
+		{
+			name:              "context",
+			ctx:               ContextWithNamespace(context.Background(), "tenant-a"),
+			expectedNamespace: "tenant-a",
+		},
+		{
+			name:              "attribute",
+			resolver:          NamespaceFromAttribute("tenant"),
+			ctx:               ContextWithNamespace(context.Background(), "tenant-a"),
+			evalCtx:           map[string]interface{}{"tenant": "tenant-b"},
+			expectedNamespace: "tenant-b",
+		},
+		{
+			name:              "resolver falls back",
+			resolver:          NamespaceFromAttribute("tenant"),
+			ctx:               ContextWithNamespace(context.Background(), "tenant-a"),
+			expectedNamespace: "tenant-a",
+		},
+		{
+			name: "resolver uses flag key",
+			resolver: func(_ context.Context, flagKey string, _ of.FlattenedContext) string {
+				return "ns-" + flagKey
+			},
+			ctx:               context.Background(),
+			expectedNamespace: "ns-string-flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockService(t)
+			mockSvc.On("Evaluate", mock.Anything, tt.expectedNamespace, "string-flag", mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+				Match:      true,
+				Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				VariantKey: "abc",
+			}, nil)
+
+			opts := []Option{WithService(mockSvc), ForNamespace("flipt")}
+			if tt.resolver != nil {
+				opts = append(opts, WithNamespaceResolver(tt.resolver))
+			}
+
+			p := NewProvider(opts...)
+
+			detail := p.StringEvaluation(tt.ctx, "string-flag", "default", tt.evalCtx)
+			assert.Equal(t, "abc", detail.Value)
+			assert.Equal(t, tt.expectedNamespace, detail.FlagMetadata[MetadataNamespace])
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package flipt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DecodeOption is an option for decoding object flags with ObjectValue.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	strict bool
+}
+
+// Strict rejects attachments containing fields which are unknown to the target type.
+func Strict() DecodeOption {
+	return func(o *decodeOptions) {
+		o.strict = true
+	}
+}
+
+// ObjectValue evaluates an object flag using the client and decodes the value
+// it resolves to into a value of type T.
+//
+// The Flipt provider decodes the attachment of the matched variant from its raw
+// JSON, so that any JSON value can be decoded and integers keep their precision.
+// Structs are decoded honoring their json tags, while proto.Message values are
+// decoded using the protobuf JSON mapping. Attachments which aren't valid JSON
+// resolve to a PARSE_ERROR and attachments which can't be decoded into T to a
+// TYPE_MISMATCH resolution error, which are reported to hooks like any failed
+// evaluation. Values resolved by other providers are decoded from their JSON
+// encoding, failing with a TYPE_MISMATCH resolution error when they can't be.
+// The default value is returned along with the error when the evaluation or
+// the decoding fails.
+func ObjectValue[T any](ctx context.Context, client of.IClient, flag string, defaultValue T, evalCtx of.EvaluationContext, opts ...DecodeOption) (T, error) {
+	decoder := &decodeTarget[T]{defaultValue: defaultValue}
+	for _, opt := range opts {
+		opt(&decoder.opts)
+	}
+
+	value, err := client.ObjectValue(context.WithValue(ctx, decoderKey{}, decoder), flag, defaultValue, evalCtx)
+	if err != nil {
+		return defaultValue, err
+	}
+
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return defaultValue, of.NewTypeMismatchResolutionError(fmt.Sprintf("encoding value %T: %v", value, err))
+	}
+
+	out, err := decode[T](b, defaultValue, decoder.opts)
+	if err != nil {
+		return defaultValue, of.NewTypeMismatchResolutionError(fmt.Sprintf("decoding value into %T: %v", defaultValue, err))
+	}
+
+	return out, nil
+}
+
+type decoderKey struct{}
+
+// attachmentDecoder is set on the context of object evaluations by ObjectValue,
+// so that the provider decodes attachments into the requested type.
+type attachmentDecoder interface {
+	decodeAttachment(attachment string) (interface{}, error)
+}
+
+type decodeTarget[T any] struct {
+	defaultValue T
+	opts         decodeOptions
+}
+
+func (d *decodeTarget[T]) decodeAttachment(attachment string) (interface{}, error) {
+	if !json.Valid([]byte(attachment)) {
+		return nil, of.NewParseErrorResolutionError(fmt.Sprintf("value is not valid JSON: %q", attachment))
+	}
+
+	out, err := decode[T]([]byte(attachment), d.defaultValue, d.opts)
+	if err != nil {
+		return nil, of.NewTypeMismatchResolutionError(fmt.Sprintf("decoding value into %T: %v", d.defaultValue, err))
+	}
+
+	return out, nil
+}
+
+func decode[T any](b []byte, defaultValue T, o decodeOptions) (T, error) {
+	var out T
+
+	if m, ok := any(defaultValue).(proto.Message); ok {
+		msg := m.ProtoReflect().New().Interface()
+
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: !o.strict}).Unmarshal(b, msg); err != nil {
+			return out, err
+		}
+
+		out, _ = msg.(T)
+
+		return out, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if o.strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&out); err != nil {
+		return out, err
+	}
+
+	return out, nil
+}
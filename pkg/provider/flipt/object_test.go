@@ -0,0 +1,179 @@
+package flipt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/types/known/apipb"
+)
+
+type objectConfig struct {
+	Name    string `json:"name"`
+	Retries int    `json:"max_retries"`
+}
+
+func TestObjectValue(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(nil, nil).Maybe()
+
+	for flag, attachment := range map[string]string{
+		"config":  `{"name": "fast", "max_retries": 3}`,
+		"unknown": `{"name": "fast", "max_retries": 3, "timeout": 10}`,
+		"invalid": `{"name": "fast", "max_retries": "three"}`,
+		"method":  `{"name": "Get", "requestStreaming": true, "unknown": 1}`,
+		"large":   `{"id": 9007199254740993, "nested": {"id": 9007199254740993}}`,
+		"list":    `["a", "b"]`,
+		"scalar":  `42`,
+		"broken":  `{"name": `,
+	} {
+		mockSvc.On("Evaluate", mock.Anything, "default", flag, mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+			Match:             true,
+			Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+			VariantKey:        flag,
+			VariantAttachment: attachment,
+		}, nil)
+	}
+
+	mockSvc.On("Evaluate", mock.Anything, "default", "disabled", mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+		Reason: evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON,
+	}, nil)
+
+	hook := &errorHook{}
+
+	require.NoError(t, of.SetNamedProvider(t.Name(), NewProvider(WithService(mockSvc), WithHooks(hook))))
+
+	var (
+		ctx          = context.Background()
+		client       = of.NewClient(t.Name())
+		evalCtx      = of.NewEvaluationContext("entity", nil)
+		defaultValue = objectConfig{Name: "default"}
+	)
+
+	v, err := ObjectValue(ctx, client, "config", defaultValue, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, objectConfig{Name: "fast", Retries: 3}, v)
+
+	v, err = ObjectValue(ctx, client, "unknown", defaultValue, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, objectConfig{Name: "fast", Retries: 3}, v)
+
+	v, err = ObjectValue(ctx, client, "unknown", defaultValue, evalCtx, Strict())
+	assert.ErrorContains(t, err, `TYPE_MISMATCH: decoding value into flipt.objectConfig: json: unknown field "timeout"`)
+	assert.Equal(t, defaultValue, v)
+
+	v, err = ObjectValue(ctx, client, "invalid", defaultValue, evalCtx)
+	assert.ErrorContains(t, err, "TYPE_MISMATCH")
+	assert.Equal(t, defaultValue, v)
+
+	v, err = ObjectValue(ctx, client, "disabled", defaultValue, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, v)
+
+	m, err := ObjectValue(ctx, client, "method", &apipb.Method{}, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "Get", m.GetName())
+	assert.True(t, m.GetRequestStreaming())
+
+	_, err = ObjectValue(ctx, client, "method", &apipb.Method{}, evalCtx, Strict())
+	assert.ErrorContains(t, err, "TYPE_MISMATCH")
+
+	// integers keep their precision, also when decoded into interface{} values.
+	type large struct {
+		ID     int64                  `json:"id"`
+		Nested map[string]interface{} `json:"nested"`
+	}
+
+	l, err := ObjectValue(ctx, client, "large", large{}, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9007199254740993), l.ID)
+	assert.Equal(t, json.Number("9007199254740993"), l.Nested["id"])
+
+	// attachments don't need to be objects.
+	list, err := ObjectValue(ctx, client, "list", []string(nil), evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, list)
+
+	n, err := ObjectValue(ctx, client, "scalar", 0, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 42, n)
+
+	_, err = ObjectValue(ctx, client, "broken", defaultValue, evalCtx)
+	assert.ErrorContains(t, err, "PARSE_ERROR")
+
+	// decoding failures are reported to hooks as failed evaluations.
+	assert.Equal(t, []string{"unknown", "invalid", "method", "broken"}, hook.flags)
+
+	// hooks see the default value of the caller.
+	assert.Equal(t, defaultValue, hook.defaultValues["config"])
+}
+
+func TestObjectValue_OtherProvider(t *testing.T) {
+	require.NoError(t, of.SetNamedProvider(t.Name(), objectProvider{values: map[string]interface{}{
+		"config":  map[string]interface{}{"name": "fast", "max_retries": 3},
+		"invalid": map[string]interface{}{"name": "fast", "max_retries": "three"},
+		"func":    func() {},
+	}}))
+
+	var (
+		ctx          = context.Background()
+		client       = of.NewClient(t.Name())
+		evalCtx      = of.NewEvaluationContext("entity", nil)
+		defaultValue = objectConfig{Name: "default"}
+	)
+
+	v, err := ObjectValue(ctx, client, "config", defaultValue, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, objectConfig{Name: "fast", Retries: 3}, v)
+
+	v, err = ObjectValue(ctx, client, "invalid", defaultValue, evalCtx)
+	assert.ErrorContains(t, err, "TYPE_MISMATCH: decoding value into flipt.objectConfig")
+	assert.Equal(t, defaultValue, v)
+
+	v, err = ObjectValue(ctx, client, "func", defaultValue, evalCtx)
+	assert.ErrorContains(t, err, "TYPE_MISMATCH: encoding value func()")
+	assert.Equal(t, defaultValue, v)
+
+	v, err = ObjectValue(ctx, client, "missing", defaultValue, evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, v)
+}
+
+type errorHook struct {
+	of.UnimplementedHook
+	flags         []string
+	defaultValues map[string]interface{}
+}
+
+func (h *errorHook) Before(_ context.Context, hookContext of.HookContext, _ of.HookHints) (*of.EvaluationContext, error) {
+	if h.defaultValues == nil {
+		h.defaultValues = map[string]interface{}{}
+	}
+
+	h.defaultValues[hookContext.FlagKey()] = hookContext.DefaultValue()
+
+	return nil, nil
+}
+
+func (h *errorHook) Error(_ context.Context, hookContext of.HookContext, _ error, _ of.HookHints) {
+	h.flags = append(h.flags, hookContext.FlagKey())
+}
+
+// objectProvider resolves object flags to the values of the map, or to their default value.
+type objectProvider struct {
+	of.NoopProvider
+	values map[string]interface{}
+}
+
+func (p objectProvider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx of.FlattenedContext) of.InterfaceResolutionDetail {
+	if v, ok := p.values[flag]; ok {
+		return of.InterfaceResolutionDetail{Value: v, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.StaticReason}}
+	}
+
+	return p.NoopProvider.ObjectEvaluation(ctx, flag, defaultValue, evalCtx)
+}
@@ -0,0 +1,148 @@
+package flipt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+type prefetchContextKey struct{}
+
+type prefetchKey struct {
+	namespaceKey string
+	flagKey      string
+}
+
+// prefetched holds the batch evaluation results of an evaluation context.
+type prefetched struct {
+	contextHash string
+	responses   map[prefetchKey]*evaluation.EvaluationResponse
+}
+
+// Prefetch evaluates the given flags with evalCtx in a single request per namespace
+// and returns a copy of ctx carrying the results. Evaluations of these flags
+// with the returned context and the same evaluation context are served from
+// the results instead of calling Flipt; any other evaluation is unaffected.
+//
+// Results of an earlier Prefetch of the same evaluation context carried by ctx are kept.
+// It fails with errors.ErrUnsupported when the service doesn't implement BatchEvaluator.
+func (p *Provider) Prefetch(ctx context.Context, flagKeys []string, evalCtx of.FlattenedContext) (context.Context, error) {
+	if _, ok := p.base.(BatchEvaluator); !ok {
+		return ctx, fmt.Errorf("prefetching flags: %w", errors.ErrUnsupported)
+	}
+
+	hash, err := hashContext(evalCtx)
+	if err != nil {
+		return ctx, fmt.Errorf("prefetching flags: %w", err)
+	}
+
+	results := &prefetched{
+		contextHash: hash,
+		responses:   map[prefetchKey]*evaluation.EvaluationResponse{},
+	}
+
+	if parent, ok := ctx.Value(prefetchContextKey{}).(*prefetched); ok && parent.contextHash == hash {
+		for key, resp := range parent.responses {
+			results.responses[key] = resp
+		}
+	}
+
+	var (
+		namespaces []string
+		flags      = map[string][]string{}
+	)
+
+	for _, flagKey := range flagKeys {
+		namespace := p.namespace(ctx, flagKey, evalCtx)
+		if _, ok := flags[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+
+		flags[namespace] = append(flags[namespace], flagKey)
+	}
+
+	for _, namespace := range namespaces {
+		resp, err := p.svc.Batch(ctx, namespace, flags[namespace], evalCtx)
+		if err != nil {
+			return ctx, fmt.Errorf("prefetching flags: %w", err)
+		}
+
+		// responses are in the order of the requests.
+		for i, r := range resp.GetResponses() {
+			if i < len(flags[namespace]) {
+				results.responses[prefetchKey{namespace, flags[namespace][i]}] = r
+			}
+		}
+	}
+
+	return context.WithValue(ctx, prefetchContextKey{}, results), nil
+}
+
+// prefetchService serves evaluations from the results of Provider.Prefetch carried
+// by the context before delegating to the wrapped Service.
+type prefetchService struct {
+	wrapper
+}
+
+func (s *prefetchService) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	if resp, ok := lookupPrefetched(ctx, namespaceKey, flagKey, evalCtx); ok {
+		if err := responseError(resp); err != nil {
+			return nil, err
+		}
+
+		if v := resp.GetVariantResponse(); v != nil {
+			return v, nil
+		}
+	}
+
+	return s.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+}
+
+func (s *prefetchService) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	if resp, ok := lookupPrefetched(ctx, namespaceKey, flagKey, evalCtx); ok {
+		if err := responseError(resp); err != nil {
+			return nil, err
+		}
+
+		if b := resp.GetBooleanResponse(); b != nil {
+			return b, nil
+		}
+	}
+
+	return s.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+}
+
+func lookupPrefetched(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.EvaluationResponse, bool) {
+	results, ok := ctx.Value(prefetchContextKey{}).(*prefetched)
+	if !ok {
+		return nil, false
+	}
+
+	resp, ok := results.responses[prefetchKey{namespaceKey, flagKey}]
+	if !ok {
+		return nil, false
+	}
+
+	if hash, err := hashContext(evalCtx); err != nil || hash != results.contextHash {
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// responseError returns the resolution error of a batch error response.
+func responseError(resp *evaluation.EvaluationResponse) error {
+	e := resp.GetErrorResponse()
+	if e == nil {
+		return nil
+	}
+
+	if e.Reason == evaluation.ErrorEvaluationReason_NOT_FOUND_ERROR_EVALUATION_REASON {
+		return of.NewFlagNotFoundResolutionError(fmt.Sprintf("flag \"%s/%s\" not found", e.NamespaceKey, e.FlagKey))
+	}
+
+	return of.NewGeneralResolutionError(fmt.Sprintf("evaluating flag \"%s/%s\" failed", e.NamespaceKey, e.FlagKey))
+}
@@ -0,0 +1,92 @@
+package flipt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestPrefetch(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	mockSvc := newMockService(t)
+	mockSvc.EXPECT().Batch(mock.Anything, "flipt", []string{"string-flag", "boolean-flag", "missing"}, evalCtx).Return(&evaluation.BatchEvaluationResponse{
+		Responses: []*evaluation.EvaluationResponse{
+			{
+				Type: evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_VariantResponse{
+					VariantResponse: &evaluation.VariantEvaluationResponse{
+						Match:      true,
+						Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+						VariantKey: "abc",
+					},
+				},
+			},
+			{
+				Type: evaluation.EvaluationResponseType_BOOLEAN_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_BooleanResponse{
+					BooleanResponse: &evaluation.BooleanEvaluationResponse{
+						Enabled: true,
+						Reason:  evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+					},
+				},
+			},
+			{
+				Type: evaluation.EvaluationResponseType_ERROR_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_ErrorResponse{
+					ErrorResponse: &evaluation.ErrorEvaluationResponse{
+						FlagKey:      "missing",
+						NamespaceKey: "flipt",
+						Reason:       evaluation.ErrorEvaluationReason_NOT_FOUND_ERROR_EVALUATION_REASON,
+					},
+				},
+			},
+		},
+	}, nil).Once()
+
+	// evaluations with another evaluation context are not served from the prefetched results.
+	mockSvc.EXPECT().Evaluate(mock.Anything, "flipt", "string-flag", map[string]interface{}{of.TargetingKey: "other"}).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "def",
+	}, nil).Once()
+
+	p := NewProvider(WithService(mockSvc), ForNamespace("flipt"))
+
+	ctx, err := p.Prefetch(context.Background(), []string{"string-flag", "boolean-flag", "missing"}, evalCtx)
+	require.NoError(t, err)
+
+	s := p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "abc", s.Value)
+	assert.Equal(t, of.TargetingMatchReason, s.Reason)
+
+	b := p.BooleanEvaluation(ctx, "boolean-flag", false, evalCtx)
+	assert.True(t, b.Value)
+	assert.Equal(t, of.SplitReason, b.Reason)
+
+	m := p.StringEvaluation(ctx, "missing", "default", evalCtx)
+	assert.Equal(t, "default", m.Value)
+	assert.Equal(t, of.NewFlagNotFoundResolutionError(`flag "flipt/missing" not found`), m.ResolutionError)
+
+	o := p.StringEvaluation(ctx, "string-flag", "default", map[string]interface{}{of.TargetingKey: "other"})
+	assert.Equal(t, "def", o.Value)
+}
+
+func TestPrefetch_Error(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.EXPECT().Batch(mock.Anything, "default", []string{"string-flag"}, mock.Anything).Return(nil, errors.New("unavailable"))
+
+	p := NewProvider(WithService(mockSvc))
+
+	ctx := context.Background()
+
+	actual, err := p.Prefetch(ctx, []string{"string-flag"}, map[string]interface{}{of.TargetingKey: "entity"})
+	assert.EqualError(t, err, "prefetching flags: unavailable")
+	assert.Equal(t, ctx, actual)
+}
@@ -4,18 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"io"
+	"sync"
+	"time"
 
 	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
 	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/transport"
 	flipt "go.flipt.io/flipt/rpc/flipt"
 	"go.flipt.io/flipt/rpc/flipt/evaluation"
 	sdk "go.flipt.io/flipt/sdk/go"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-var _ of.FeatureProvider = (*Provider)(nil)
+var (
+	_ of.FeatureProvider = (*Provider)(nil)
+	_ of.StateHandler    = (*Provider)(nil)
+)
+
+const defaultInitTimeout = 10 * time.Second
 
 // Config is a configuration for the FliptProvider.
 type Config struct {
@@ -52,7 +62,7 @@ func WithConfig(config Config) Option {
 // WithService is an Option to set the service for the Provider.
 func WithService(svc Service) Option {
 	return func(p *Provider) {
-		p.svc = svc
+		p.base = svc
 	}
 }
 
@@ -71,330 +81,471 @@ func ForNamespace(namespace string) Option {
 	}
 }
 
+// WithInitTimeout sets the maximum duration Init waits for Flipt to become reachable.
+func WithInitTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.initTimeout = timeout
+	}
+}
+
+// WithLocalEvaluation evaluates flags in-process from a snapshot of each
+// namespace instead of calling Flipt for every evaluation. Snapshots are
+// refreshed at the given interval and the last successfully loaded snapshot
+// keeps being served while Flipt is unreachable, which is reported as a
+// PROVIDER_STALE event until a refresh succeeds.
+// It has no effect when combined with WithService.
+func WithLocalEvaluation(refreshInterval time.Duration) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts,
+			transport.WithLocalEvaluation(refreshInterval),
+			transport.WithRefreshListener(p.onRefresh),
+		)
+	}
+}
+
+// onRefresh translates the outcome of refreshing local evaluation state into provider events.
+func (p *Provider) onRefresh(err error) {
+	if err != nil {
+		p.transitionWith(of.StaleState, of.ProviderStale, staleDetails("refreshing flag state", err), of.ReadyState)
+
+		return
+	}
+
+	p.transition(of.ReadyState, of.ProviderReady, "flag state refreshed", of.StaleState)
+}
+
+// WithPropagators sets the propagators used to inject the trace context into requests to Flipt.
+// It has no effect when combined with WithService.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithPropagators(propagators))
+	}
+}
+
+// WithTracerProvider sets the provider of the tracer recording the spans of requests to Flipt.
+// It has no effect when combined with WithService.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithTracerProvider(tracerProvider))
+	}
+}
+
+// WithRetryPolicy retries calls to Flipt failing with a transient error according to the given policy.
+// Use transport.DefaultRetryPolicy for sensible defaults.
+// It has no effect when combined with WithService.
+func WithRetryPolicy(policy transport.RetryPolicy) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithRetryPolicy(policy))
+	}
+}
+
+// WithContextEncoder sets the encoder converting evaluation contexts to the string values sent to Flipt.
+// Use it to flatten nested attributes or to register encoders for custom types.
+// It has no effect when combined with WithService.
+func WithContextEncoder(encoder *transport.ContextEncoder) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithContextEncoder(encoder))
+	}
+}
+
+// WithContextFilter filters the evaluation context attributes sent to Flipt, for example
+// to keep personal data out of Flipt or to hash the targeting key.
+// It has no effect when combined with WithService.
+func WithContextFilter(filter *transport.ContextFilter) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithContextFilter(filter))
+	}
+}
+
+// WithRequestIDGenerator sets the generator of the IDs of evaluation requests whose context
+// doesn't carry one set with transport.ContextWithRequestID. Request IDs are reported in
+// the MetadataRequestID flag metadata. By default the ID of the current trace is used,
+// or a random UUID outside of a trace.
+// It has no effect when combined with WithService.
+func WithRequestIDGenerator(generator transport.RequestIDGenerator) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithRequestIDGenerator(generator))
+	}
+}
+
+// WithEntityIDResolver sets the resolver of the entity ID of evaluations whose context lacks
+// a targeting key, such as transport.AttributeEntityID, transport.CompositeEntityID or
+// transport.RandomEntityID. Such evaluations fail with a TARGETING_KEY_MISSING error by default.
+// It has no effect when combined with WithService.
+func WithEntityIDResolver(resolver transport.EntityIDResolver) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithEntityIDResolver(resolver))
+	}
+}
+
+// WithAddresses sets an ordered list of addresses of Flipt replicas, which may mix gRPC and HTTP.
+// Evaluations are routed to the first healthy address and fail over to the next ones
+// while it's unavailable. It replaces the address set with WithAddress.
+// It has no effect when combined with WithService.
+func WithAddresses(addresses ...string) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithAddresses(addresses...))
+	}
+}
+
+// WithFailoverListener registers a listener called with the previous and the new address
+// whenever evaluations switch between the addresses set with WithAddresses.
+// It has no effect when combined with WithService.
+func WithFailoverListener(listener transport.FailoverListener) Option {
+	return func(p *Provider) {
+		p.transportOpts = append(p.transportOpts, transport.WithFailoverListener(listener))
+	}
+}
+
+// WithHooks sets hooks which run on every evaluation of flags by the provider.
+// See the hooks package for ready-made hooks.
+func WithHooks(hooks ...of.Hook) Option {
+	return func(p *Provider) {
+		p.hooks = append(p.hooks, hooks...)
+	}
+}
+
 // NewProvider returns a new Flipt provider.
 func NewProvider(opts ...Option) *Provider {
-	p := &Provider{config: Config{
-		Address:   "http://localhost:8080",
-		Namespace: "default",
-	}}
+	p := &Provider{
+		config: Config{
+			Address:   "http://localhost:8080",
+			Namespace: "default",
+		},
+		initTimeout: defaultInitTimeout,
+		status:      of.NotReadyState,
+		events:      make(chan of.Event, eventBufferSize),
+	}
 
 	for _, opt := range opts {
 		opt(p)
 	}
 
-	if p.svc == nil {
-		topts := []transport.Option{transport.WithAddress(p.config.Address), transport.WithCertificatePath(p.config.CertificatePath)}
+	if p.base == nil {
+		topts := []transport.Option{
+			transport.WithAddress(p.config.Address),
+			transport.WithCertificatePath(p.config.CertificatePath),
+			transport.WithStateListener(p.onTransportState),
+		}
 		if p.config.TokenProvider != nil {
 			topts = append(topts, transport.WithClientTokenProvider(p.config.TokenProvider))
 		}
 
-		p.svc = transport.New(topts...)
+		topts = append(topts, p.transportOpts...)
+
+		p.base = transport.New(topts...)
+	}
+
+	svc := p.base
+
+	if p.timeout != nil {
+		p.timeout.Service = svc
+		svc = p.timeout
+	}
+
+	if p.metrics != nil {
+		svc = &meteredService{wrapper: wrapper{svc}, metrics: p.metrics}
 	}
 
+	if p.breaker != nil {
+		p.breaker.Service = svc
+		svc = p.breaker
+	}
+
+	if p.fallback != nil {
+		p.fallback.Service = svc
+		svc = p.fallback
+	}
+
+	if p.cache != nil {
+		p.cache.Service = svc
+		svc = p.cache
+	}
+
+	p.svc = &prefetchService{wrapper: wrapper{svc}}
+
 	return p
 }
 
-//go:generate mockery --name=Service --structname=mockService --case=underscore --output=. --outpkg=flipt --filename=provider_support.go --testonly --with-expecter --disable-version-string
 type Service interface {
 	GetFlag(ctx context.Context, namespaceKey, flagKey string) (*flipt.Flag, error)
 	Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error)
 	Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error)
 }
 
-// Provider implements the FeatureProvider interface and provides functions for evaluating flags with Flipt.
-type Provider struct {
-	svc    Service
-	config Config
+// NamespaceGetter is implemented by services which can retrieve namespaces.
+// Init verifies that the configured namespace exists when the Service implements it.
+type NamespaceGetter interface {
+	GetNamespace(ctx context.Context, namespaceKey string) (*flipt.Namespace, error)
 }
 
-// Metadata returns the metadata of the provider.
-func (p Provider) Metadata() of.Metadata {
-	return of.Metadata{Name: "flipt-provider"}
+// FlagLister is implemented by services which can list the flags of a namespace, as ResolveAll requires.
+type FlagLister interface {
+	ListFlags(ctx context.Context, namespaceKey string) ([]*flipt.Flag, error)
 }
 
-// BooleanEvaluation returns a boolean flag.
-func (p Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx of.FlattenedContext) of.BoolResolutionDetail {
-	resp, err := p.svc.Boolean(ctx, p.config.Namespace, flag, evalCtx)
-	if err != nil {
-		var (
-			rerr   of.ResolutionError
-			detail = of.BoolResolutionDetail{
-				Value: defaultValue,
-				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
-				},
-			}
-		)
-
-		if errors.As(err, &rerr) {
-			detail.ProviderResolutionDetail.ResolutionError = rerr
+// Snapshotter is implemented by services which can load the flags of a namespace
+// with their rules and rollouts, as the flag watcher requires.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, namespaceKey string) (*local.Snapshot, error)
+}
 
-			return detail
-		}
+// BatchEvaluator is implemented by services which can evaluate several flags
+// in a single request, as Prefetch and ResolveAll require.
+type BatchEvaluator interface {
+	Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error)
+}
 
-		detail.ProviderResolutionDetail.ResolutionError = of.NewGeneralResolutionError(err.Error())
+//go:generate mockery --name=fullService --structname=mockService --case=underscore --output=. --outpkg=flipt --filename=provider_support.go --testonly --with-expecter --disable-version-string
+type fullService interface {
+	Service
+	NamespaceGetter
+	FlagLister
+	Snapshotter
+	BatchEvaluator
+}
 
-		return detail
-	}
+var _ fullService = wrapper{}
 
-	return of.BoolResolutionDetail{
-		Value: resp.Enabled,
-		ProviderResolutionDetail: of.ProviderResolutionDetail{
-			Reason: of.TargetingMatchReason,
-		},
-	}
+// wrapper is embedded by the services wrapping another Service and forwards the
+// optional methods to it, failing with errors.ErrUnsupported when it doesn't implement them.
+type wrapper struct {
+	Service
 }
 
-// StringEvaluation returns a string flag.
-func (p Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx of.FlattenedContext) of.StringResolutionDetail {
-	resp, err := p.svc.Evaluate(ctx, p.config.Namespace, flag, evalCtx)
-	if err != nil {
-		var (
-			rerr   of.ResolutionError
-			detail = of.StringResolutionDetail{
-				Value: defaultValue,
-				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
-				},
-			}
-		)
+func (w wrapper) GetNamespace(ctx context.Context, namespaceKey string) (*flipt.Namespace, error) {
+	s, ok := w.Service.(NamespaceGetter)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement NamespaceGetter: %w", w.Service, errors.ErrUnsupported)
+	}
 
-		if errors.As(err, &rerr) {
-			detail.ProviderResolutionDetail.ResolutionError = rerr
+	return s.GetNamespace(ctx, namespaceKey)
+}
 
-			return detail
-		}
+func (w wrapper) ListFlags(ctx context.Context, namespaceKey string) ([]*flipt.Flag, error) {
+	s, ok := w.Service.(FlagLister)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement FlagLister: %w", w.Service, errors.ErrUnsupported)
+	}
 
-		detail.ProviderResolutionDetail.ResolutionError = of.NewGeneralResolutionError(err.Error())
+	return s.ListFlags(ctx, namespaceKey)
+}
 
-		return detail
+func (w wrapper) Snapshot(ctx context.Context, namespaceKey string) (*local.Snapshot, error) {
+	s, ok := w.Service.(Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement Snapshotter: %w", w.Service, errors.ErrUnsupported)
 	}
 
-	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON {
-		return of.StringResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DisabledReason,
-			},
-		}
-	}
+	return s.Snapshot(ctx, namespaceKey)
+}
 
-	if !resp.Match {
-		return of.StringResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DefaultReason,
-			},
-		}
+func (w wrapper) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	s, ok := w.Service.(BatchEvaluator)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement BatchEvaluator: %w", w.Service, errors.ErrUnsupported)
 	}
 
-	return of.StringResolutionDetail{
-		Value: resp.VariantKey,
-		ProviderResolutionDetail: of.ProviderResolutionDetail{
-			Reason: of.TargetingMatchReason,
-		},
-	}
+	return s.Batch(ctx, namespaceKey, flagKeys, evalCtx)
 }
 
-// FloatEvaluation returns a float flag.
-func (p Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx of.FlattenedContext) of.FloatResolutionDetail {
-	resp, err := p.svc.Evaluate(ctx, p.config.Namespace, flag, evalCtx)
-	if err != nil {
-		var (
-			rerr   of.ResolutionError
-			detail = of.FloatResolutionDetail{
-				Value: defaultValue,
-				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
-				},
-			}
-		)
+// Provider implements the FeatureProvider, StateHandler and EventHandler interfaces and provides functions for evaluating flags with Flipt.
+type Provider struct {
+	svc           fullService
+	base          Service
+	cache         *cachedService
+	config        Config
+	initTimeout   time.Duration
+	watchInterval time.Duration
+	events        chan of.Event
+	transportOpts []transport.Option
+	hooks         []of.Hook
+	metrics       *metrics
+	fallback      *fallbackService
+	breaker       *circuitBreaker
+	timeout       *timeoutService
+
+	valueSource       ValueSource
+	flagValueSources  map[string]ValueSource
+	namespaceResolver NamespaceResolver
+
+	mu          sync.RWMutex
+	status      of.State
+	stopWatcher func()
+
+	eventsMu       sync.Mutex
+	queued         []of.Event
+	stopForwarding chan struct{}
+}
 
-		if errors.As(err, &rerr) {
-			detail.ProviderResolutionDetail.ResolutionError = rerr
+// Metadata returns the metadata of the provider.
+func (p *Provider) Metadata() of.Metadata {
+	return of.Metadata{Name: "flipt-provider"}
+}
 
-			return detail
-		}
+// Init starts the underlying service, connects to Flipt and verifies that the
+// configured namespace can be retrieved, if the service implements NamespaceGetter.
+// The provider is READY when this succeeds and in ERROR otherwise.
+// The flag watcher, when configured, is started in either case.
+func (p *Provider) Init(evalCtx of.EvaluationContext) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.initTimeout)
+	defer cancel()
 
-		detail.ProviderResolutionDetail.ResolutionError = of.NewGeneralResolutionError(err.Error())
+	defer p.startWatcher()
 
-		return detail
+	if starter, ok := p.base.(interface{ Start() }); ok {
+		starter.Start()
 	}
 
-	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON {
-		return of.FloatResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DisabledReason,
-			},
-		}
-	}
+	if _, ok := p.base.(NamespaceGetter); ok {
+		if _, err := p.svc.GetNamespace(ctx, p.config.Namespace); err != nil {
+			p.setStatus(of.ErrorState)
 
-	if !resp.Match {
-		return of.FloatResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DefaultReason,
-			},
+			return fmt.Errorf("initializing provider: %w", err)
 		}
 	}
 
-	fv, err := strconv.ParseFloat(resp.VariantKey, 64)
-	if err != nil {
-		return of.FloatResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				ResolutionError: of.NewTypeMismatchResolutionError("value is not a float"),
-				Reason:          of.ErrorReason,
-			},
-		}
+	p.setStatus(of.ReadyState)
+
+	return nil
+}
+
+// Shutdown stops the flag watcher and releases the resources held by the underlying service.
+func (p *Provider) Shutdown() {
+	p.mu.Lock()
+	stop := p.stopWatcher
+	p.stopWatcher = nil
+	p.status = of.NotReadyState
+	p.mu.Unlock()
+
+	if stop != nil {
+		stop()
 	}
 
-	return of.FloatResolutionDetail{
-		Value: fv,
-		ProviderResolutionDetail: of.ProviderResolutionDetail{
-			Reason: of.TargetingMatchReason,
-		},
+	p.stopEvents()
+
+	if closer, ok := p.base.(io.Closer); ok {
+		_ = closer.Close()
 	}
 }
 
-// IntEvaluation returns an int flag.
-func (p Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx of.FlattenedContext) of.IntResolutionDetail {
-	resp, err := p.svc.Evaluate(ctx, p.config.Namespace, flag, evalCtx)
-	if err != nil {
-		var (
-			rerr   of.ResolutionError
-			detail = of.IntResolutionDetail{
-				Value: defaultValue,
-				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
-				},
-			}
-		)
+// Status returns the current state of the provider.
+func (p *Provider) Status() of.State {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-		if errors.As(err, &rerr) {
-			detail.ProviderResolutionDetail.ResolutionError = rerr
+	return p.status
+}
 
-			return detail
-		}
+func (p *Provider) setStatus(status of.State) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-		detail.ProviderResolutionDetail.ResolutionError = of.NewGeneralResolutionError(err.Error())
+	p.status = status
+}
 
-		return detail
-	}
+// BooleanEvaluation returns a boolean flag.
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx of.FlattenedContext) of.BoolResolutionDetail {
+	start := time.Now()
+	namespace := p.namespace(ctx, flag, evalCtx)
+	ctx = withServed(ctx)
+	resp, err := p.svc.Boolean(ctx, namespace, flag, evalCtx)
 
-	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON {
-		return of.IntResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DisabledReason,
-			},
-		}
-	}
+	value, detail := resolveBoolean(namespace, resp, err, servedReason(ctx), defaultValue)
 
-	if !resp.Match {
-		return of.IntResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DefaultReason,
-			},
-		}
-	}
+	p.metrics.evaluated(ctx, flag, start, detail)
 
-	iv, err := strconv.ParseInt(resp.VariantKey, 10, 64)
-	if err != nil {
-		return of.IntResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				ResolutionError: of.NewTypeMismatchResolutionError("value is not an integer"),
-				Reason:          of.ErrorReason,
-			},
-		}
+	return of.BoolResolutionDetail{
+		Value:                    value,
+		ProviderResolutionDetail: detail,
 	}
+}
 
-	return of.IntResolutionDetail{
-		Value: iv,
-		ProviderResolutionDetail: of.ProviderResolutionDetail{
-			Reason: of.TargetingMatchReason,
-		},
+// StringEvaluation returns a string flag.
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx of.FlattenedContext) of.StringResolutionDetail {
+	start := time.Now()
+	namespace := p.namespace(ctx, flag, evalCtx)
+	ctx = withServed(ctx)
+	resp, err := p.svc.Evaluate(ctx, namespace, flag, evalCtx)
+
+	value, detail := resolveVariant(namespace, resp, err, servedReason(ctx), defaultValue, p.valueSourceFor(flag).stringValue)
+
+	p.metrics.evaluated(ctx, flag, start, detail)
+
+	return of.StringResolutionDetail{
+		Value:                    value,
+		ProviderResolutionDetail: detail,
 	}
 }
 
-// ObjectEvaluation returns an object flag with attachment if any. Value is a map of key/value pairs ([string]interface{}).
-func (p Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx of.FlattenedContext) of.InterfaceResolutionDetail {
-	resp, err := p.svc.Evaluate(ctx, p.config.Namespace, flag, evalCtx)
-	if err != nil {
-		var (
-			rerr   of.ResolutionError
-			detail = of.InterfaceResolutionDetail{
-				Value: defaultValue,
-				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
-				},
-			}
-		)
-
-		if errors.As(err, &rerr) {
-			detail.ProviderResolutionDetail.ResolutionError = rerr
+// FloatEvaluation returns a float flag.
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx of.FlattenedContext) of.FloatResolutionDetail {
+	start := time.Now()
+	namespace := p.namespace(ctx, flag, evalCtx)
+	ctx = withServed(ctx)
+	resp, err := p.svc.Evaluate(ctx, namespace, flag, evalCtx)
 
-			return detail
-		}
+	value, detail := resolveVariant(namespace, resp, err, servedReason(ctx), defaultValue, p.valueSourceFor(flag).floatValue)
 
-		detail.ProviderResolutionDetail.ResolutionError = of.NewGeneralResolutionError(err.Error())
+	p.metrics.evaluated(ctx, flag, start, detail)
 
-		return detail
+	return of.FloatResolutionDetail{
+		Value:                    value,
+		ProviderResolutionDetail: detail,
 	}
+}
 
-	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON {
-		return of.InterfaceResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DisabledReason,
-			},
-		}
+// IntEvaluation returns an int flag.
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx of.FlattenedContext) of.IntResolutionDetail {
+	start := time.Now()
+	namespace := p.namespace(ctx, flag, evalCtx)
+	ctx = withServed(ctx)
+	resp, err := p.svc.Evaluate(ctx, namespace, flag, evalCtx)
+
+	value, detail := resolveVariant(namespace, resp, err, servedReason(ctx), defaultValue, p.valueSourceFor(flag).intValue)
+
+	p.metrics.evaluated(ctx, flag, start, detail)
+
+	return of.IntResolutionDetail{
+		Value:                    value,
+		ProviderResolutionDetail: detail,
 	}
+}
 
-	if !resp.Match {
-		return of.InterfaceResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason: of.DefaultReason,
-			},
+// ObjectEvaluation returns an object flag with attachment if any. Value is a map of key/value pairs ([string]interface{}).
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx of.FlattenedContext) of.InterfaceResolutionDetail {
+	start := time.Now()
+	namespace := p.namespace(ctx, flag, evalCtx)
+	ctx = withServed(ctx)
+	resp, err := p.svc.Evaluate(ctx, namespace, flag, evalCtx)
+
+	value, detail := resolveVariant(namespace, resp, err, servedReason(ctx), defaultValue, func(resp *evaluation.VariantEvaluationResponse) (interface{}, error) {
+		if resp.VariantAttachment == "" {
+			return nil, errNoValue
 		}
-	}
 
-	if resp.VariantAttachment == "" {
-		return of.InterfaceResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				Reason:  of.DefaultReason,
-				Variant: resp.VariantKey,
-			},
+		// attachments are decoded into the type requested with ObjectValue.
+		if decoder, ok := ctx.Value(decoderKey{}).(attachmentDecoder); ok {
+			return decoder.decodeAttachment(resp.VariantAttachment)
 		}
-	}
 
-	out := new(structpb.Struct)
-	if err := protojson.Unmarshal([]byte(resp.VariantAttachment), out); err != nil {
-		return of.InterfaceResolutionDetail{
-			Value: defaultValue,
-			ProviderResolutionDetail: of.ProviderResolutionDetail{
-				ResolutionError: of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not an object: %q", resp.VariantAttachment)),
-				Reason:          of.ErrorReason,
-			},
+		out := new(structpb.Struct)
+		if err := protojson.Unmarshal([]byte(resp.VariantAttachment), out); err != nil {
+			return nil, of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not an object: %q", resp.VariantAttachment))
 		}
-	}
+
+		return out.AsMap(), nil
+	})
+
+	p.metrics.evaluated(ctx, flag, start, detail)
 
 	return of.InterfaceResolutionDetail{
-		Value: out.AsMap(),
-		ProviderResolutionDetail: of.ProviderResolutionDetail{
-			Reason:  of.TargetingMatchReason,
-			Variant: resp.VariantKey,
-		},
+		Value:                    value,
+		ProviderResolutionDetail: detail,
 	}
 }
 
-// Hooks returns hooks.
-func (p Provider) Hooks() []of.Hook {
-	// code to retrieve hooks
-	return []of.Hook{}
+// Hooks returns the hooks set with WithHooks.
+func (p *Provider) Hooks() []of.Hook {
+	return append([]of.Hook{}, p.hooks...)
 }
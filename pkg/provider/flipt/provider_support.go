@@ -7,12 +7,14 @@ import (
 
 	evaluation "go.flipt.io/flipt/rpc/flipt/evaluation"
 
+	local "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
+
 	mock "github.com/stretchr/testify/mock"
 
 	rpcflipt "go.flipt.io/flipt/rpc/flipt"
 )
 
-// mockService is an autogenerated mock type for the Service type
+// mockService is an autogenerated mock type for the fullService type
 type mockService struct {
 	mock.Mock
 }
@@ -25,6 +27,63 @@ func (_m *mockService) EXPECT() *mockService_Expecter {
 	return &mockService_Expecter{mock: &_m.Mock}
 }
 
+// Batch provides a mock function with given fields: ctx, namespaceKey, flagKeys, evalCtx
+func (_m *mockService) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	ret := _m.Called(ctx, namespaceKey, flagKeys, evalCtx)
+
+	var r0 *evaluation.BatchEvaluationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, map[string]interface{}) (*evaluation.BatchEvaluationResponse, error)); ok {
+		return rf(ctx, namespaceKey, flagKeys, evalCtx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, map[string]interface{}) *evaluation.BatchEvaluationResponse); ok {
+		r0 = rf(ctx, namespaceKey, flagKeys, evalCtx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*evaluation.BatchEvaluationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, map[string]interface{}) error); ok {
+		r1 = rf(ctx, namespaceKey, flagKeys, evalCtx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockService_Batch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Batch'
+type mockService_Batch_Call struct {
+	*mock.Call
+}
+
+// Batch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - namespaceKey string
+//   - flagKeys []string
+//   - evalCtx map[string]interface{}
+func (_e *mockService_Expecter) Batch(ctx interface{}, namespaceKey interface{}, flagKeys interface{}, evalCtx interface{}) *mockService_Batch_Call {
+	return &mockService_Batch_Call{Call: _e.mock.On("Batch", ctx, namespaceKey, flagKeys, evalCtx)}
+}
+
+func (_c *mockService_Batch_Call) Run(run func(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{})) *mockService_Batch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(map[string]interface{}))
+	})
+	return _c
+}
+
+func (_c *mockService_Batch_Call) Return(_a0 *evaluation.BatchEvaluationResponse, _a1 error) *mockService_Batch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockService_Batch_Call) RunAndReturn(run func(context.Context, string, []string, map[string]interface{}) (*evaluation.BatchEvaluationResponse, error)) *mockService_Batch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Boolean provides a mock function with given fields: ctx, namespaceKey, flagKey, evalCtx
 func (_m *mockService) Boolean(ctx context.Context, namespaceKey string, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
 	ret := _m.Called(ctx, namespaceKey, flagKey, evalCtx)
@@ -195,6 +254,171 @@ func (_c *mockService_GetFlag_Call) RunAndReturn(run func(context.Context, strin
 	return _c
 }
 
+// GetNamespace provides a mock function with given fields: ctx, namespaceKey
+func (_m *mockService) GetNamespace(ctx context.Context, namespaceKey string) (*rpcflipt.Namespace, error) {
+	ret := _m.Called(ctx, namespaceKey)
+
+	var r0 *rpcflipt.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*rpcflipt.Namespace, error)); ok {
+		return rf(ctx, namespaceKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *rpcflipt.Namespace); ok {
+		r0 = rf(ctx, namespaceKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rpcflipt.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespaceKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockService_GetNamespace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNamespace'
+type mockService_GetNamespace_Call struct {
+	*mock.Call
+}
+
+// GetNamespace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - namespaceKey string
+func (_e *mockService_Expecter) GetNamespace(ctx interface{}, namespaceKey interface{}) *mockService_GetNamespace_Call {
+	return &mockService_GetNamespace_Call{Call: _e.mock.On("GetNamespace", ctx, namespaceKey)}
+}
+
+func (_c *mockService_GetNamespace_Call) Run(run func(ctx context.Context, namespaceKey string)) *mockService_GetNamespace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *mockService_GetNamespace_Call) Return(_a0 *rpcflipt.Namespace, _a1 error) *mockService_GetNamespace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockService_GetNamespace_Call) RunAndReturn(run func(context.Context, string) (*rpcflipt.Namespace, error)) *mockService_GetNamespace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFlags provides a mock function with given fields: ctx, namespaceKey
+func (_m *mockService) ListFlags(ctx context.Context, namespaceKey string) ([]*rpcflipt.Flag, error) {
+	ret := _m.Called(ctx, namespaceKey)
+
+	var r0 []*rpcflipt.Flag
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*rpcflipt.Flag, error)); ok {
+		return rf(ctx, namespaceKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*rpcflipt.Flag); ok {
+		r0 = rf(ctx, namespaceKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*rpcflipt.Flag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespaceKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockService_ListFlags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFlags'
+type mockService_ListFlags_Call struct {
+	*mock.Call
+}
+
+// ListFlags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - namespaceKey string
+func (_e *mockService_Expecter) ListFlags(ctx interface{}, namespaceKey interface{}) *mockService_ListFlags_Call {
+	return &mockService_ListFlags_Call{Call: _e.mock.On("ListFlags", ctx, namespaceKey)}
+}
+
+func (_c *mockService_ListFlags_Call) Run(run func(ctx context.Context, namespaceKey string)) *mockService_ListFlags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *mockService_ListFlags_Call) Return(_a0 []*rpcflipt.Flag, _a1 error) *mockService_ListFlags_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockService_ListFlags_Call) RunAndReturn(run func(context.Context, string) ([]*rpcflipt.Flag, error)) *mockService_ListFlags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Snapshot provides a mock function with given fields: ctx, namespaceKey
+func (_m *mockService) Snapshot(ctx context.Context, namespaceKey string) (*local.Snapshot, error) {
+	ret := _m.Called(ctx, namespaceKey)
+
+	var r0 *local.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*local.Snapshot, error)); ok {
+		return rf(ctx, namespaceKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *local.Snapshot); ok {
+		r0 = rf(ctx, namespaceKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*local.Snapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespaceKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// mockService_Snapshot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Snapshot'
+type mockService_Snapshot_Call struct {
+	*mock.Call
+}
+
+// Snapshot is a helper method to define mock.On call
+//   - ctx context.Context
+//   - namespaceKey string
+func (_e *mockService_Expecter) Snapshot(ctx interface{}, namespaceKey interface{}) *mockService_Snapshot_Call {
+	return &mockService_Snapshot_Call{Call: _e.mock.On("Snapshot", ctx, namespaceKey)}
+}
+
+func (_c *mockService_Snapshot_Call) Run(run func(ctx context.Context, namespaceKey string)) *mockService_Snapshot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *mockService_Snapshot_Call) Return(_a0 *local.Snapshot, _a1 error) *mockService_Snapshot_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *mockService_Snapshot_Call) RunAndReturn(run func(context.Context, string) (*local.Snapshot, error)) *mockService_Snapshot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 type mockConstructorTestingTnewMockService interface {
 	mock.TestingT
 	Cleanup(func())
@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	of "github.com/open-feature/go-sdk/pkg/openfeature"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	flipt "go.flipt.io/flipt/rpc/flipt"
 	"go.flipt.io/flipt/rpc/flipt/evaluation"
 )
 
@@ -17,6 +20,93 @@ func TestMetadata(t *testing.T) {
 	assert.Equal(t, "flipt-provider", p.Metadata().Name)
 }
 
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockRespErr    error
+		expectedStatus of.State
+		expectedErr    string
+	}{
+		{
+			name:           "ready",
+			expectedStatus: of.ReadyState,
+		},
+		{
+			name:           "unavailable",
+			mockRespErr:    of.NewProviderNotReadyResolutionError("connection refused"),
+			expectedStatus: of.ErrorState,
+			expectedErr:    "initializing provider: PROVIDER_NOT_READY: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockService(t)
+			mockSvc.On("GetNamespace", mock.Anything, "flipt").Return(&flipt.Namespace{Key: "flipt"}, tt.mockRespErr)
+
+			p := NewProvider(WithService(mockSvc), ForNamespace("flipt"))
+			assert.Equal(t, of.NotReadyState, p.Status())
+
+			err := p.Init(of.EvaluationContext{})
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expectedStatus, p.Status())
+		})
+	}
+}
+
+type closingService struct {
+	*mockService
+	closed bool
+}
+
+func (c *closingService) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestShutdown(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("GetNamespace", mock.Anything, "default").Return(&flipt.Namespace{Key: "default"}, nil)
+
+	svc := &closingService{mockService: mockSvc}
+
+	p := NewProvider(WithService(svc))
+	assert.NoError(t, p.Init(of.EvaluationContext{}))
+	assert.Equal(t, of.ReadyState, p.Status())
+
+	p.Shutdown()
+
+	assert.True(t, svc.closed, "service should be closed on shutdown")
+	assert.Equal(t, of.NotReadyState, p.Status())
+}
+
+// evaluatingService only implements the methods of Service.
+type evaluatingService struct {
+	Service
+}
+
+func TestOptionalServiceMethods(t *testing.T) {
+	p := NewProvider(WithService(evaluatingService{newMockService(t)}), WithWatchInterval(10*time.Millisecond))
+
+	// the namespace isn't verified and the flags aren't watched.
+	require.NoError(t, p.Init(of.EvaluationContext{}))
+	defer p.Shutdown()
+
+	assert.Equal(t, of.ReadyState, p.Status())
+	assert.Nil(t, p.stopWatcher)
+
+	_, err := p.ResolveAll(context.Background(), of.FlattenedContext{})
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+
+	_, err = p.Prefetch(context.Background(), []string{"flag"}, of.FlattenedContext{})
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
 func TestBooleanEvaluation(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -34,7 +124,17 @@ func TestBooleanEvaluation(t *testing.T) {
 				Enabled: false,
 				Reason:  evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
 			},
-			expected: of.BoolResolutionDetail{Value: false, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.TargetingMatchReason}},
+			expected: of.BoolResolutionDetail{Value: false, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.SplitReason, Variant: "false"}},
+		},
+		{
+			name:         "flag disabled",
+			flagKey:      "boolean-disabled",
+			defaultValue: true,
+			mockRespEvaluation: &evaluation.BooleanEvaluationResponse{
+				Enabled: false,
+				Reason:  evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON,
+			},
+			expected: of.BoolResolutionDetail{Value: true, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.DisabledReason}},
 		},
 		{
 			name:                  "resolution error",
@@ -44,7 +144,7 @@ func TestBooleanEvaluation(t *testing.T) {
 			expected: of.BoolResolutionDetail{
 				Value: false,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewInvalidContextResolutionError("boom"),
 				},
 			},
@@ -60,6 +160,11 @@ func TestBooleanEvaluation(t *testing.T) {
 
 			actual := p.BooleanEvaluation(context.Background(), tt.flagKey, tt.defaultValue, map[string]interface{}{})
 
+			assert.Equal(t, "flipt", actual.FlagMetadata[MetadataNamespace])
+
+			// flag metadata is covered by TestFlagMetadata.
+			actual.FlagMetadata = nil
+
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
@@ -82,7 +187,7 @@ func TestStringEvaluation(t *testing.T) {
 				Match:      true,
 				VariantKey: "true",
 			},
-			expected: of.StringResolutionDetail{Value: "true", ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.TargetingMatchReason}},
+			expected: of.StringResolutionDetail{Value: "true", ProviderResolutionDetail: of.ProviderResolutionDetail{Variant: "true", Reason: of.TargetingMatchReason}},
 		},
 		{
 			name:         "flag disabled",
@@ -102,7 +207,7 @@ func TestStringEvaluation(t *testing.T) {
 			expected: of.StringResolutionDetail{
 				Value: "true",
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewInvalidContextResolutionError("boom"),
 				},
 			},
@@ -115,7 +220,7 @@ func TestStringEvaluation(t *testing.T) {
 			expected: of.StringResolutionDetail{
 				Value: "true",
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewGeneralResolutionError("boom"),
 				},
 			},
@@ -142,7 +247,8 @@ func TestStringEvaluation(t *testing.T) {
 			expected: of.StringResolutionDetail{
 				Value: "abc",
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "abc",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -157,7 +263,8 @@ func TestStringEvaluation(t *testing.T) {
 			expected: of.StringResolutionDetail{
 				Value: "abc",
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "abc",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -172,6 +279,11 @@ func TestStringEvaluation(t *testing.T) {
 
 			actual := p.StringEvaluation(context.Background(), tt.flagKey, tt.defaultValue, map[string]interface{}{})
 
+			assert.Equal(t, "default", actual.FlagMetadata[MetadataNamespace])
+
+			// flag metadata is covered by TestFlagMetadata.
+			actual.FlagMetadata = nil
+
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
@@ -195,7 +307,7 @@ func TestFloatEvaluation(t *testing.T) {
 				Match:      true,
 				VariantKey: "1.0",
 			},
-			expected: of.FloatResolutionDetail{Value: 1.0, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.TargetingMatchReason}},
+			expected: of.FloatResolutionDetail{Value: 1.0, ProviderResolutionDetail: of.ProviderResolutionDetail{Variant: "1.0", Reason: of.TargetingMatchReason}},
 		},
 		{
 			name:    "flag disabled",
@@ -216,7 +328,7 @@ func TestFloatEvaluation(t *testing.T) {
 			expected: of.FloatResolutionDetail{
 				Value: 0.0,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewInvalidContextResolutionError("boom"),
 				},
 			},
@@ -233,6 +345,7 @@ func TestFloatEvaluation(t *testing.T) {
 			expected: of.FloatResolutionDetail{
 				Value: 1.0,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
+					Variant:         "not-a-float",
 					Reason:          of.ErrorReason,
 					ResolutionError: of.NewTypeMismatchResolutionError("value is not a float"),
 				},
@@ -246,7 +359,7 @@ func TestFloatEvaluation(t *testing.T) {
 			expected: of.FloatResolutionDetail{
 				Value: 1.0,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewGeneralResolutionError("boom"),
 				},
 			},
@@ -273,7 +386,8 @@ func TestFloatEvaluation(t *testing.T) {
 			expected: of.FloatResolutionDetail{
 				Value: 2.0,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "2.0",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -288,6 +402,11 @@ func TestFloatEvaluation(t *testing.T) {
 
 			actual := p.FloatEvaluation(context.Background(), tt.flagKey, tt.defaultValue, map[string]interface{}{})
 
+			assert.Equal(t, "flipt", actual.FlagMetadata[MetadataNamespace])
+
+			// flag metadata is covered by TestFlagMetadata.
+			actual.FlagMetadata = nil
+
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
@@ -311,7 +430,7 @@ func TestIntEvaluation(t *testing.T) {
 				Match:      true,
 				VariantKey: "1",
 			},
-			expected: of.IntResolutionDetail{Value: 1, ProviderResolutionDetail: of.ProviderResolutionDetail{Reason: of.TargetingMatchReason}},
+			expected: of.IntResolutionDetail{Value: 1, ProviderResolutionDetail: of.ProviderResolutionDetail{Variant: "1", Reason: of.TargetingMatchReason}},
 		},
 		{
 			name:    "flag disabled",
@@ -332,7 +451,7 @@ func TestIntEvaluation(t *testing.T) {
 			expected: of.IntResolutionDetail{
 				Value: 0,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewInvalidContextResolutionError("boom"),
 				},
 			},
@@ -349,6 +468,7 @@ func TestIntEvaluation(t *testing.T) {
 			expected: of.IntResolutionDetail{
 				Value: 1,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
+					Variant:         "not-an-int",
 					Reason:          of.ErrorReason,
 					ResolutionError: of.NewTypeMismatchResolutionError("value is not an integer"),
 				},
@@ -362,7 +482,7 @@ func TestIntEvaluation(t *testing.T) {
 			expected: of.IntResolutionDetail{
 				Value: 1,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewGeneralResolutionError("boom"),
 				},
 			},
@@ -389,7 +509,8 @@ func TestIntEvaluation(t *testing.T) {
 			expected: of.IntResolutionDetail{
 				Value: 2,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "2",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -404,7 +525,8 @@ func TestIntEvaluation(t *testing.T) {
 			expected: of.IntResolutionDetail{
 				Value: 2,
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "2",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -419,6 +541,11 @@ func TestIntEvaluation(t *testing.T) {
 
 			actual := p.IntEvaluation(context.Background(), tt.flagKey, tt.defaultValue, map[string]interface{}{})
 
+			assert.Equal(t, "default", actual.FlagMetadata[MetadataNamespace])
+
+			// flag metadata is covered by TestFlagMetadata.
+			actual.FlagMetadata = nil
+
 			assert.Equal(t, tt.expected, actual)
 		})
 	}
@@ -486,7 +613,7 @@ func TestObjectEvaluation(t *testing.T) {
 				Value: map[string]interface{}{
 					"baz": "qux",
 				}, ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewInvalidContextResolutionError("boom"),
 				},
 			},
@@ -525,7 +652,7 @@ func TestObjectEvaluation(t *testing.T) {
 					"baz": "qux",
 				},
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason:          of.DefaultReason,
+					Reason:          of.ErrorReason,
 					ResolutionError: of.NewGeneralResolutionError("boom"),
 				},
 			},
@@ -555,6 +682,7 @@ func TestObjectEvaluation(t *testing.T) {
 				"baz": "qux",
 			},
 			mockRespEvaluation: &evaluation.VariantEvaluationResponse{
+				Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
 				Match:             true,
 				VariantKey:        "2",
 				VariantAttachment: "{\"foo\": \"bar\"}",
@@ -564,7 +692,8 @@ func TestObjectEvaluation(t *testing.T) {
 					"foo": "bar",
 				},
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.TargetingMatchReason,
+					Variant: "2",
+					Reason:  of.TargetingMatchReason,
 				},
 			},
 		},
@@ -584,7 +713,8 @@ func TestObjectEvaluation(t *testing.T) {
 					"baz": "qux",
 				},
 				ProviderResolutionDetail: of.ProviderResolutionDetail{
-					Reason: of.DefaultReason,
+					Variant: "2",
+					Reason:  of.DefaultReason,
 				},
 			},
 		},
@@ -603,3 +733,10 @@ func TestObjectEvaluation(t *testing.T) {
 		})
 	}
 }
+
+func TestHooks(t *testing.T) {
+	assert.Empty(t, NewProvider().Hooks())
+
+	hook := of.UnimplementedHook{}
+	assert.Equal(t, []of.Hook{hook}, NewProvider(WithHooks(hook)).Hooks())
+}
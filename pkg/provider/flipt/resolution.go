@@ -0,0 +1,206 @@
+package flipt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// reasonKey identifies the outcome of a Flipt evaluation of a flag type.
+type reasonKey struct {
+	boolean bool
+	reason  evaluation.EvaluationReason
+}
+
+// reasons maps the reasons of Flipt evaluations to OpenFeature reasons.
+//
+// Variant flags match rules targeting segments, while boolean flags only match
+// rollouts, which split traffic by percentage threshold or segment. When no
+// variant matched the default value is returned, whereas boolean flags resolve
+// to the enabled state of the flag when no rollout matched.
+var reasons = map[reasonKey]of.Reason{
+	{reason: evaluation.EvaluationReason_MATCH_EVALUATION_REASON}:                        of.TargetingMatchReason,
+	{reason: evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON}:                      of.DefaultReason,
+	{reason: evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON}:                of.DisabledReason,
+	{reason: evaluation.EvaluationReason_UNKNOWN_EVALUATION_REASON}:                      of.DefaultReason,
+	{boolean: true, reason: evaluation.EvaluationReason_MATCH_EVALUATION_REASON}:         of.SplitReason,
+	{boolean: true, reason: evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON}:       of.StaticReason,
+	{boolean: true, reason: evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON}: of.DisabledReason,
+	{boolean: true, reason: evaluation.EvaluationReason_UNKNOWN_EVALUATION_REASON}:       of.UnknownReason,
+}
+
+// errNoValue is returned by a variant value converter when the matched variant has no value,
+// in which case the default value is returned.
+var errNoValue = errors.New("variant has no value")
+
+func mapReason(boolean bool, reason evaluation.EvaluationReason) of.Reason {
+	if r, ok := reasons[reasonKey{boolean: boolean, reason: reason}]; ok {
+		return r
+	}
+
+	return of.UnknownReason
+}
+
+// variantReason returns the Flipt reason of a variant evaluation. Flipt servers
+// predating evaluation reasons report UNKNOWN for matches.
+func variantReason(resp *evaluation.VariantEvaluationResponse) evaluation.EvaluationReason {
+	if resp.Match && resp.Reason == evaluation.EvaluationReason_UNKNOWN_EVALUATION_REASON {
+		return evaluation.EvaluationReason_MATCH_EVALUATION_REASON
+	}
+
+	return resp.Reason
+}
+
+// resolveVariant resolves the outcome of a variant evaluation into a value using convert.
+// The default value is returned when the evaluation failed, the flag is disabled,
+// no variant matched or the variant can't be converted.
+// Responses which were served rather than evaluated by Flipt, such as cached
+// or stale responses, are resolved as usual and reported with the served reason.
+func resolveVariant[T any](
+	namespace string,
+	resp *evaluation.VariantEvaluationResponse,
+	err error,
+	served of.Reason,
+	defaultValue T,
+	convert func(*evaluation.VariantEvaluationResponse) (T, error),
+) (T, of.ProviderResolutionDetail) {
+	if err != nil {
+		return defaultValue, errorDetail(namespace, err)
+	}
+
+	if served != "" {
+		value, detail := resolveVariant(namespace, resp, nil, "", defaultValue, convert)
+		if detail.Reason != of.ErrorReason {
+			detail.Reason = served
+		}
+
+		if served == of.CachedReason {
+			dropRequestMetadata(detail.FlagMetadata)
+		}
+
+		return value, detail
+	}
+
+	detail := of.ProviderResolutionDetail{
+		Reason:       mapReason(false, variantReason(resp)),
+		FlagMetadata: variantMetadata(namespace, resp),
+	}
+
+	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON || !resp.Match {
+		return defaultValue, detail
+	}
+
+	detail.Variant = resp.VariantKey
+
+	value, err := convert(resp)
+	switch {
+	case errors.Is(err, errNoValue):
+		detail.Reason = of.DefaultReason
+
+		return defaultValue, detail
+	case err != nil:
+		detail.Reason = of.ErrorReason
+		detail.ResolutionError = toResolutionError(err)
+
+		return defaultValue, detail
+	}
+
+	return value, detail
+}
+
+// resolveBoolean resolves the outcome of a boolean evaluation. Boolean values are
+// reported as the variants "true" and "false". The default value is returned
+// when the evaluation failed or the flag is disabled.
+func resolveBoolean(namespace string, resp *evaluation.BooleanEvaluationResponse, err error, served of.Reason, defaultValue bool) (bool, of.ProviderResolutionDetail) {
+	if err != nil {
+		return defaultValue, errorDetail(namespace, err)
+	}
+
+	reason := mapReason(true, resp.GetReason())
+	if served != "" {
+		reason = served
+	}
+
+	metadata := booleanMetadata(namespace, resp)
+	if reason == of.CachedReason {
+		dropRequestMetadata(metadata)
+	}
+
+	if resp.Reason == evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON {
+		return defaultValue, of.ProviderResolutionDetail{
+			Reason:       reason,
+			FlagMetadata: metadata,
+		}
+	}
+
+	return resp.Enabled, of.ProviderResolutionDetail{
+		Reason:       reason,
+		Variant:      strconv.FormatBool(resp.Enabled),
+		FlagMetadata: metadata,
+	}
+}
+
+type servedKey struct{}
+
+// withServed returns a copy of ctx on which wrapping services mark responses
+// which they served rather than Flipt evaluated, such as cached responses.
+func withServed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, servedKey{}, new(of.Reason))
+}
+
+// markServed marks the response of the evaluation of ctx as served with the given reason.
+func markServed(ctx context.Context, reason of.Reason) {
+	if served, ok := ctx.Value(servedKey{}).(*of.Reason); ok {
+		*served = reason
+	}
+}
+
+// servedReason returns the reason the response of the evaluation of ctx was
+// served with, or an empty reason when it was evaluated by Flipt.
+func servedReason(ctx context.Context) of.Reason {
+	if served, ok := ctx.Value(servedKey{}).(*of.Reason); ok {
+		return *served
+	}
+
+	return ""
+}
+
+// dropRequestMetadata removes the metadata of the request which evaluated a
+// response from the flag metadata of its later resolutions.
+func dropRequestMetadata(metadata of.FlagMetadata) {
+	delete(metadata, MetadataRequestID)
+	delete(metadata, MetadataTimestamp)
+	delete(metadata, MetadataRequestDurationMillis)
+}
+
+// errorDetail returns the resolution detail of a failed evaluation.
+func errorDetail(namespace string, err error) of.ProviderResolutionDetail {
+	metadata := namespaceMetadata(namespace)
+	if errors.Is(err, ErrEvaluationTimeout) {
+		metadata[MetadataTimedOut] = true
+
+		return of.ProviderResolutionDetail{
+			Reason:          of.ErrorReason,
+			ResolutionError: of.NewGeneralResolutionError(err.Error()),
+			FlagMetadata:    metadata,
+		}
+	}
+
+	return of.ProviderResolutionDetail{
+		Reason:          of.ErrorReason,
+		ResolutionError: toResolutionError(err),
+		FlagMetadata:    metadata,
+	}
+}
+
+func toResolutionError(err error) of.ResolutionError {
+	var rerr of.ResolutionError
+	if errors.As(err, &rerr) {
+		return rerr
+	}
+
+	return of.NewGeneralResolutionError(err.Error())
+}
@@ -0,0 +1,102 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestReasonMapping(t *testing.T) {
+	tests := []struct {
+		name            string
+		reason          evaluation.EvaluationReason
+		match           bool
+		expectedVariant of.Reason
+		expectedBoolean of.Reason
+		expectedValue   bool
+	}{
+		{
+			name:            "match",
+			reason:          evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+			match:           true,
+			expectedVariant: of.TargetingMatchReason,
+			expectedBoolean: of.SplitReason,
+			expectedValue:   true,
+		},
+		{
+			name:            "match without reason",
+			reason:          evaluation.EvaluationReason_UNKNOWN_EVALUATION_REASON,
+			match:           true,
+			expectedVariant: of.TargetingMatchReason,
+			expectedBoolean: of.UnknownReason,
+			expectedValue:   true,
+		},
+		{
+			name:            "default",
+			reason:          evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON,
+			expectedVariant: of.DefaultReason,
+			expectedBoolean: of.StaticReason,
+			expectedValue:   true,
+		},
+		{
+			name:            "disabled",
+			reason:          evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON,
+			expectedVariant: of.DisabledReason,
+			expectedBoolean: of.DisabledReason,
+		},
+		{
+			name:            "unknown",
+			reason:          evaluation.EvaluationReason_UNKNOWN_EVALUATION_REASON,
+			expectedVariant: of.DefaultReason,
+			expectedBoolean: of.UnknownReason,
+			expectedValue:   true,
+		},
+		{
+			name:            "unmapped",
+			reason:          evaluation.EvaluationReason(42),
+			expectedVariant: of.UnknownReason,
+			expectedBoolean: of.UnknownReason,
+			expectedValue:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockService(t)
+			mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+				Match:      tt.match,
+				Reason:     tt.reason,
+				VariantKey: "abc",
+			}, nil)
+			mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", mock.Anything).Return(&evaluation.BooleanEvaluationResponse{
+				Enabled: true,
+				Reason:  tt.reason,
+			}, nil)
+
+			p := NewProvider(WithService(mockSvc))
+
+			sdetail := p.StringEvaluation(context.Background(), "string-flag", "default", nil)
+			assert.Equal(t, tt.expectedVariant, sdetail.Reason)
+
+			if tt.match {
+				assert.Equal(t, "abc", sdetail.Value)
+			} else {
+				assert.Equal(t, "default", sdetail.Value)
+			}
+
+			detail := p.BooleanEvaluation(context.Background(), "boolean-flag", false, nil)
+			assert.Equal(t, tt.expectedBoolean, detail.Reason)
+			assert.Equal(t, tt.expectedValue, detail.Value)
+
+			if tt.expectedValue {
+				assert.Equal(t, "true", detail.Variant)
+			} else {
+				assert.Empty(t, detail.Variant)
+			}
+		})
+	}
+}
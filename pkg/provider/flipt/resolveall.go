@@ -0,0 +1,130 @@
+package flipt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// Resolution is the outcome of evaluating a flag with ResolveAll.
+// It marshals to JSON so that it can be handed to clients, such as a page
+// bootstrapping a frontend SDK. encoding/json escapes HTML characters, which
+// makes the output safe to embed in a script element.
+type Resolution struct {
+	// Value is a bool for boolean flags and the string value of the matched
+	// variant, read according to the value source of the flag, for variant flags.
+	Value        interface{}     `json:"value"`
+	Variant      string          `json:"variant,omitempty"`
+	Reason       of.Reason       `json:"reason"`
+	Attachment   json.RawMessage `json:"attachment,omitempty"`
+	ErrorCode    of.ErrorCode    `json:"errorCode,omitempty"`
+	ErrorMessage string          `json:"errorMessage,omitempty"`
+}
+
+// ResolveAll evaluates every flag of a namespace with evalCtx in a single batch
+// and returns the resolutions by flag key. Flags are evaluated according to
+// their type. The namespace is resolved as for evaluations, with an empty flag key.
+//
+// Failures to evaluate a single flag are reported in its resolution; an error
+// is returned when the flags can't be listed or evaluated at all, including with
+// errors.ErrUnsupported when the service doesn't implement FlagLister and BatchEvaluator.
+func (p *Provider) ResolveAll(ctx context.Context, evalCtx of.FlattenedContext) (map[string]Resolution, error) {
+	if _, ok := p.base.(FlagLister); !ok {
+		return nil, fmt.Errorf("listing flags: %w", errors.ErrUnsupported)
+	}
+
+	if _, ok := p.base.(BatchEvaluator); !ok {
+		return nil, fmt.Errorf("evaluating flags: %w", errors.ErrUnsupported)
+	}
+
+	namespace := p.namespace(ctx, "", evalCtx)
+
+	flags, err := p.svc.ListFlags(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("listing flags: %w", err)
+	}
+
+	resolutions := make(map[string]Resolution, len(flags))
+	if len(flags) == 0 {
+		return resolutions, nil
+	}
+
+	keys := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		keys = append(keys, flag.Key)
+	}
+
+	resp, err := p.svc.Batch(ctx, namespace, keys, evalCtx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating flags: %w", err)
+	}
+
+	responses := resp.GetResponses()
+
+	for i, flag := range flags {
+		var r *evaluation.EvaluationResponse
+		if i < len(responses) {
+			r = responses[i]
+		}
+
+		resolutions[flag.Key] = p.resolution(namespace, flag, r)
+	}
+
+	return resolutions, nil
+}
+
+// resolution resolves the batch evaluation response of a flag.
+func (p *Provider) resolution(namespace string, flag *flipt.Flag, resp *evaluation.EvaluationResponse) Resolution {
+	err := responseError(resp)
+	if err == nil && resp == nil {
+		err = of.NewGeneralResolutionError(fmt.Sprintf("no response for flag \"%s/%s\"", namespace, flag.Key))
+	}
+
+	if flag.Type == flipt.FlagType_BOOLEAN_FLAG_TYPE {
+		b := resp.GetBooleanResponse()
+		if err == nil && b == nil {
+			err = of.NewTypeMismatchResolutionError(fmt.Sprintf("unexpected response type %v", resp.GetType()))
+		}
+
+		value, detail := resolveBoolean(namespace, b, err, "", false)
+
+		return newResolution(value, detail, "")
+	}
+
+	v := resp.GetVariantResponse()
+	if err == nil && v == nil {
+		err = of.NewTypeMismatchResolutionError(fmt.Sprintf("unexpected response type %v", resp.GetType()))
+	}
+
+	value, detail := resolveVariant(namespace, v, err, "", "", p.valueSourceFor(flag.Key).stringValue)
+
+	var attachment string
+	if detail.Variant != "" {
+		attachment = v.VariantAttachment
+	}
+
+	return newResolution(value, detail, attachment)
+}
+
+func newResolution(value interface{}, detail of.ProviderResolutionDetail, attachment string) Resolution {
+	d := detail.ResolutionDetail()
+
+	r := Resolution{
+		Value:        value,
+		Variant:      d.Variant,
+		Reason:       d.Reason,
+		ErrorCode:    d.ErrorCode,
+		ErrorMessage: d.ErrorMessage,
+	}
+
+	if attachment != "" && json.Valid([]byte(attachment)) {
+		r.Attachment = json.RawMessage(attachment)
+	}
+
+	return r
+}
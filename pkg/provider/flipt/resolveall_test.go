@@ -0,0 +1,117 @@
+package flipt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestResolveAll(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	mockSvc := newMockService(t)
+	mockSvc.EXPECT().ListFlags(mock.Anything, "flipt").Return([]*flipt.Flag{
+		{Key: "boolean-flag", Type: flipt.FlagType_BOOLEAN_FLAG_TYPE},
+		{Key: "variant-flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE},
+		{Key: "disabled-flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE},
+		{Key: "deleted-flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE},
+	}, nil)
+	mockSvc.EXPECT().Batch(mock.Anything, "flipt", []string{"boolean-flag", "variant-flag", "disabled-flag", "deleted-flag"}, evalCtx).Return(&evaluation.BatchEvaluationResponse{
+		Responses: []*evaluation.EvaluationResponse{
+			{
+				Type: evaluation.EvaluationResponseType_BOOLEAN_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_BooleanResponse{
+					BooleanResponse: &evaluation.BooleanEvaluationResponse{
+						Enabled: true,
+						Reason:  evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+					},
+				},
+			},
+			{
+				Type: evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_VariantResponse{
+					VariantResponse: &evaluation.VariantEvaluationResponse{
+						Match:             true,
+						Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+						VariantKey:        "blue",
+						VariantAttachment: `{"hex":"#0000ff"}`,
+					},
+				},
+			},
+			{
+				Type: evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_VariantResponse{
+					VariantResponse: &evaluation.VariantEvaluationResponse{
+						Reason: evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON,
+					},
+				},
+			},
+			{
+				Type: evaluation.EvaluationResponseType_ERROR_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_ErrorResponse{
+					ErrorResponse: &evaluation.ErrorEvaluationResponse{
+						FlagKey:      "deleted-flag",
+						NamespaceKey: "flipt",
+						Reason:       evaluation.ErrorEvaluationReason_NOT_FOUND_ERROR_EVALUATION_REASON,
+					},
+				},
+			},
+		},
+	}, nil)
+
+	p := NewProvider(WithService(mockSvc), ForNamespace("flipt"))
+
+	resolutions, err := p.ResolveAll(context.Background(), evalCtx)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Resolution{
+		"boolean-flag": {
+			Value:   true,
+			Variant: "true",
+			Reason:  of.SplitReason,
+		},
+		"variant-flag": {
+			Value:      "blue",
+			Variant:    "blue",
+			Reason:     of.TargetingMatchReason,
+			Attachment: json.RawMessage(`{"hex":"#0000ff"}`),
+		},
+		"disabled-flag": {
+			Value:  "",
+			Reason: of.DisabledReason,
+		},
+		"deleted-flag": {
+			Value:        "",
+			Reason:       of.ErrorReason,
+			ErrorCode:    of.FlagNotFoundCode,
+			ErrorMessage: `flag "flipt/deleted-flag" not found`,
+		},
+	}, resolutions)
+
+	b, err := json.Marshal(resolutions)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"boolean-flag": {"value": true, "variant": "true", "reason": "SPLIT"},
+		"variant-flag": {"value": "blue", "variant": "blue", "reason": "TARGETING_MATCH", "attachment": {"hex": "#0000ff"}},
+		"disabled-flag": {"value": "", "reason": "DISABLED"},
+		"deleted-flag": {"value": "", "reason": "ERROR", "errorCode": "FLAG_NOT_FOUND", "errorMessage": "flag \"flipt/deleted-flag\" not found"}
+	}`, string(b))
+}
+
+func TestResolveAll_Error(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.EXPECT().ListFlags(mock.Anything, "default").Return(nil, errors.New("unavailable"))
+
+	p := NewProvider(WithService(mockSvc))
+
+	_, err := p.ResolveAll(context.Background(), map[string]interface{}{of.TargetingKey: "entity"})
+	assert.EqualError(t, err, "listing flags: unavailable")
+}
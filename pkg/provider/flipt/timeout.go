@@ -0,0 +1,146 @@
+package flipt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// ErrEvaluationTimeout is returned by the service of the provider for evaluations which
+// exceeded their timeout. These resolve to the default value with a GENERAL resolution
+// error whose message starts with the one of ErrEvaluationTimeout, and the
+// MetadataTimedOut flag metadata.
+var ErrEvaluationTimeout = errors.New("evaluation timed out")
+
+// WithEvaluationTimeout bounds the duration of each evaluation request to Flipt.
+// Evaluations exceeding it are cancelled and fail with ErrEvaluationTimeout.
+func WithEvaluationTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.timeouts().timeout = timeout
+	}
+}
+
+// WithFlagEvaluationTimeout bounds the duration of evaluation requests of the given flag,
+// overriding the timeout set with WithEvaluationTimeout.
+// Batch evaluations of several flags are bounded by the smallest of their timeouts.
+func WithFlagEvaluationTimeout(flagKey string, timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.timeouts().flagTimeouts[flagKey] = timeout
+	}
+}
+
+func (p *Provider) timeouts() *timeoutService {
+	if p.timeout == nil {
+		p.timeout = &timeoutService{flagTimeouts: map[string]time.Duration{}}
+	}
+
+	return p.timeout
+}
+
+// timeoutError is returned by evaluations which exceeded their timeout.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s after %s", ErrEvaluationTimeout, e.timeout)
+}
+
+func (e *timeoutError) Unwrap() error {
+	return ErrEvaluationTimeout
+}
+
+// timeoutService bounds the duration of the evaluations of the wrapped Service.
+type timeoutService struct {
+	wrapper
+	timeout      time.Duration
+	flagTimeouts map[string]time.Duration
+}
+
+func (s *timeoutService) timeoutFor(flagKey string) time.Duration {
+	if timeout, ok := s.flagTimeouts[flagKey]; ok {
+		return timeout
+	}
+
+	return s.timeout
+}
+
+// batchTimeout returns the smallest of the timeouts of the given flags, or the
+// default timeout when there are none.
+func (s *timeoutService) batchTimeout(flagKeys []string) time.Duration {
+	if len(flagKeys) == 0 {
+		return s.timeout
+	}
+
+	var timeout time.Duration
+	for _, flagKey := range flagKeys {
+		t := s.timeoutFor(flagKey)
+		if t > 0 && (timeout <= 0 || t < timeout) {
+			timeout = t
+		}
+	}
+
+	return timeout
+}
+
+// withTimeout calls fn with a context which is cancelled after the timeout. It returns
+// as soon as the timeout is exceeded, even when fn doesn't return on cancellation.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+
+	// buffered, so that fn can complete after the timeout without blocking.
+	done := make(chan result, 1)
+
+	go func() {
+		v, err := fn(tctx)
+		done <- result{v, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() == nil && errors.Is(tctx.Err(), context.DeadlineExceeded) {
+			return r.v, &timeoutError{timeout: timeout}
+		}
+
+		return r.v, r.err
+	case <-tctx.Done():
+		var zero T
+
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+
+		return zero, &timeoutError{timeout: timeout}
+	}
+}
+
+func (s *timeoutService) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
+	return withTimeout(ctx, s.timeoutFor(flagKey), func(ctx context.Context) (*evaluation.VariantEvaluationResponse, error) {
+		return s.Service.Evaluate(ctx, namespaceKey, flagKey, evalCtx)
+	})
+}
+
+func (s *timeoutService) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	return withTimeout(ctx, s.timeoutFor(flagKey), func(ctx context.Context) (*evaluation.BooleanEvaluationResponse, error) {
+		return s.Service.Boolean(ctx, namespaceKey, flagKey, evalCtx)
+	})
+}
+
+func (s *timeoutService) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	return withTimeout(ctx, s.batchTimeout(flagKeys), func(ctx context.Context) (*evaluation.BatchEvaluationResponse, error) {
+		return s.wrapper.Batch(ctx, namespaceKey, flagKeys, evalCtx)
+	})
+}
@@ -0,0 +1,111 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestEvaluationTimeout(t *testing.T) {
+	var (
+		evalCtx = map[string]interface{}{of.TargetingKey: "entity"}
+		release = make(chan struct{})
+		aborted = make(chan struct{})
+	)
+
+	defer close(release)
+
+	mockSvc := newMockService(t)
+	// a call which is aborted on cancellation.
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(nil, context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+		close(aborted)
+	})
+	// a call which ignores cancellation.
+	mockSvc.On("Boolean", mock.Anything, "default", "boolean-flag", evalCtx).Return(&evaluation.BooleanEvaluationResponse{Enabled: true}, nil).Run(func(mock.Arguments) {
+		<-release
+	})
+	mockSvc.On("Evaluate", mock.Anything, "default", "slow-flag", evalCtx).Return(&evaluation.VariantEvaluationResponse{
+		Match:      true,
+		Reason:     evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey: "abc",
+	}, nil).Run(func(mock.Arguments) {
+		time.Sleep(50 * time.Millisecond)
+	})
+
+	p := NewProvider(
+		WithService(mockSvc),
+		WithEvaluationTimeout(10*time.Millisecond),
+		WithFlagEvaluationTimeout("slow-flag", time.Minute),
+	)
+
+	s := p.StringEvaluation(context.Background(), "string-flag", "default", evalCtx)
+	assert.Equal(t, "default", s.Value)
+	assert.Equal(t, of.ErrorReason, s.Reason)
+	assert.Equal(t, of.NewGeneralResolutionError("evaluation timed out after 10ms"), s.ResolutionError)
+	assert.Equal(t, true, s.FlagMetadata[MetadataTimedOut])
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("evaluation was not cancelled")
+	}
+
+	start := time.Now()
+	b := p.BooleanEvaluation(context.Background(), "boolean-flag", false, evalCtx)
+	assert.False(t, b.Value)
+	assert.Equal(t, of.NewGeneralResolutionError("evaluation timed out after 10ms"), b.ResolutionError)
+	assert.Less(t, time.Since(start), time.Second)
+
+	slow := p.StringEvaluation(context.Background(), "slow-flag", "default", evalCtx)
+	assert.Equal(t, "abc", slow.Value)
+}
+
+func TestEvaluationTimeout_Batch(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Batch", mock.Anything, "default", mock.Anything, evalCtx).Return(&evaluation.BatchEvaluationResponse{}, nil).Run(func(mock.Arguments) {
+		time.Sleep(50 * time.Millisecond)
+	})
+
+	p := NewProvider(
+		WithService(mockSvc),
+		WithEvaluationTimeout(time.Minute),
+		WithFlagEvaluationTimeout("fast-flag", 10*time.Millisecond),
+		WithFlagEvaluationTimeout("unbounded-flag", 0),
+	)
+
+	_, err := p.svc.Batch(context.Background(), "default", []string{"string-flag", "fast-flag"}, evalCtx)
+	assert.ErrorIs(t, err, ErrEvaluationTimeout)
+	assert.EqualError(t, err, "evaluation timed out after 10ms")
+
+	_, err = p.svc.Batch(context.Background(), "default", []string{"string-flag", "unbounded-flag"}, evalCtx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Minute, p.timeout.batchTimeout(nil))
+	assert.Equal(t, time.Duration(0), p.timeout.batchTimeout([]string{"unbounded-flag"}))
+}
+
+func TestEvaluationTimeout_Cancelled(t *testing.T) {
+	evalCtx := map[string]interface{}{of.TargetingKey: "entity"}
+
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", "string-flag", evalCtx).Return(nil, context.Canceled).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Maybe()
+
+	p := NewProvider(WithService(mockSvc), WithEvaluationTimeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := p.StringEvaluation(ctx, "string-flag", "default", evalCtx)
+	assert.Equal(t, "default", s.Value)
+	assert.Nil(t, s.FlagMetadata[MetadataTimedOut])
+}
@@ -0,0 +1,196 @@
+package flipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// ValueSource determines where string, integer and float evaluations read the
+// value of the matched variant from. The zero value reads the variant key.
+type ValueSource struct {
+	attachment bool
+	pointer    string
+}
+
+// VariantKey reads values from the key of the matched variant.
+var VariantKey = ValueSource{}
+
+// Attachment reads values from the JSON attachment of the matched variant.
+// The pointer is a JSON pointer (RFC 6901) to the value within the attachment,
+// e.g. "/limits/requests"; an empty pointer refers to the whole attachment,
+// which is then expected to be a bare JSON string or number.
+func Attachment(pointer string) ValueSource {
+	return ValueSource{attachment: true, pointer: pointer}
+}
+
+// WithValueSource sets where string, integer and float evaluations read values from.
+// It defaults to VariantKey.
+func WithValueSource(source ValueSource) Option {
+	return func(p *Provider) {
+		p.valueSource = source
+	}
+}
+
+// WithFlagValueSource sets where string, integer and float evaluations of the
+// given flag read values from, overriding WithValueSource.
+func WithFlagValueSource(flagKey string, source ValueSource) Option {
+	return func(p *Provider) {
+		if p.flagValueSources == nil {
+			p.flagValueSources = map[string]ValueSource{}
+		}
+
+		p.flagValueSources[flagKey] = source
+	}
+}
+
+func (p *Provider) valueSourceFor(flagKey string) ValueSource {
+	if source, ok := p.flagValueSources[flagKey]; ok {
+		return source
+	}
+
+	return p.valueSource
+}
+
+// stringValue reads a string value from the matched variant.
+func (s ValueSource) stringValue(resp *evaluation.VariantEvaluationResponse) (string, error) {
+	if !s.attachment {
+		return resp.VariantKey, nil
+	}
+
+	v, err := s.attachmentValue(resp)
+	if err != nil {
+		return "", err
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		return "", of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not a string: %v", v))
+	}
+
+	return str, nil
+}
+
+// floatValue reads a float value from the matched variant.
+func (s ValueSource) floatValue(resp *evaluation.VariantEvaluationResponse) (float64, error) {
+	if !s.attachment {
+		fv, err := strconv.ParseFloat(resp.VariantKey, 64)
+		if err != nil {
+			return 0, of.NewTypeMismatchResolutionError("value is not a float")
+		}
+
+		return fv, nil
+	}
+
+	v, err := s.attachmentValue(resp)
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not a float: %v", v))
+	}
+
+	fv, err := n.Float64()
+	if err != nil {
+		return 0, of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not a float: %v", v))
+	}
+
+	return fv, nil
+}
+
+// intValue reads an integer value from the matched variant.
+func (s ValueSource) intValue(resp *evaluation.VariantEvaluationResponse) (int64, error) {
+	if !s.attachment {
+		iv, err := strconv.ParseInt(resp.VariantKey, 10, 64)
+		if err != nil {
+			return 0, of.NewTypeMismatchResolutionError("value is not an integer")
+		}
+
+		return iv, nil
+	}
+
+	v, err := s.attachmentValue(resp)
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not an integer: %v", v))
+	}
+
+	iv, err := n.Int64()
+	if err != nil {
+		return 0, of.NewTypeMismatchResolutionError(fmt.Sprintf("value is not an integer: %v", v))
+	}
+
+	return iv, nil
+}
+
+// attachmentValue decodes the attachment of the matched variant and resolves the pointer within it.
+// Numbers are decoded as json.Number so that integers keep their precision.
+func (s ValueSource) attachmentValue(resp *evaluation.VariantEvaluationResponse) (interface{}, error) {
+	if resp.VariantAttachment == "" {
+		return nil, of.NewTypeMismatchResolutionError(fmt.Sprintf("variant %q has no attachment", resp.VariantKey))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(resp.VariantAttachment)))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, of.NewTypeMismatchResolutionError(fmt.Sprintf("attachment is not valid JSON: %q", resp.VariantAttachment))
+	}
+
+	v, err := resolvePointer(v, s.pointer)
+	if err != nil {
+		return nil, of.NewTypeMismatchResolutionError(err.Error())
+	}
+
+	return v, nil
+}
+
+// resolvePointer resolves a JSON pointer (RFC 6901) within a decoded JSON document.
+func resolvePointer(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	v := doc
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("attachment has no value at %q", pointer)
+			}
+
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("attachment has no value at %q", pointer)
+			}
+
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("attachment has no value at %q", pointer)
+		}
+	}
+
+	return v, nil
+}
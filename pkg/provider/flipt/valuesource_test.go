@@ -0,0 +1,126 @@
+package flipt
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+func TestValueSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        ValueSource
+		attachment    string
+		expectedInt   int64
+		expectedFloat float64
+		expectedStr   string
+		expectedErr   of.ResolutionError
+	}{
+		{
+			name:          "bare number",
+			source:        Attachment(""),
+			attachment:    `10`,
+			expectedInt:   10,
+			expectedFloat: 10,
+			expectedStr:   "default",
+			expectedErr:   of.NewTypeMismatchResolutionError("value is not a string: 10"),
+		},
+		{
+			name:          "pointer",
+			source:        Attachment("/limits/0/a~1b"),
+			attachment:    `{"limits": [{"a/b": 0.25}]}`,
+			expectedInt:   -1,
+			expectedFloat: 0.25,
+			expectedStr:   "default",
+			expectedErr:   of.NewTypeMismatchResolutionError("value is not an integer: 0.25"),
+		},
+		{
+			name:          "bare string",
+			source:        Attachment(""),
+			attachment:    `"high"`,
+			expectedInt:   -1,
+			expectedFloat: -1,
+			expectedStr:   "high",
+			expectedErr:   of.NewTypeMismatchResolutionError("value is not an integer: high"),
+		},
+		{
+			name:          "missing pointer",
+			source:        Attachment("/missing"),
+			attachment:    `{"limit": 10}`,
+			expectedInt:   -1,
+			expectedFloat: -1,
+			expectedStr:   "default",
+			expectedErr:   of.NewTypeMismatchResolutionError(`attachment has no value at "/missing"`),
+		},
+		{
+			name:          "no attachment",
+			source:        Attachment(""),
+			expectedInt:   -1,
+			expectedFloat: -1,
+			expectedStr:   "default",
+			expectedErr:   of.NewTypeMismatchResolutionError(`variant "high-limit" has no attachment`),
+		},
+		{
+			name:          "variant key",
+			source:        VariantKey,
+			attachment:    `10`,
+			expectedInt:   -1,
+			expectedFloat: -1,
+			expectedStr:   "high-limit",
+			expectedErr:   of.NewTypeMismatchResolutionError("value is not an integer"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := newMockService(t)
+			mockSvc.On("Evaluate", mock.Anything, "default", "limit", mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+				Match:             true,
+				Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				VariantKey:        "high-limit",
+				VariantAttachment: tt.attachment,
+			}, nil)
+
+			p := NewProvider(WithService(mockSvc), WithValueSource(tt.source))
+
+			var errs []of.ResolutionError
+
+			intDetail := p.IntEvaluation(context.Background(), "limit", -1, nil)
+			assert.Equal(t, tt.expectedInt, intDetail.Value)
+			errs = append(errs, intDetail.ResolutionError)
+
+			floatDetail := p.FloatEvaluation(context.Background(), "limit", -1, nil)
+			assert.Equal(t, tt.expectedFloat, floatDetail.Value)
+			errs = append(errs, floatDetail.ResolutionError)
+
+			strDetail := p.StringEvaluation(context.Background(), "limit", "default", nil)
+			assert.Equal(t, tt.expectedStr, strDetail.Value)
+			errs = append(errs, strDetail.ResolutionError)
+
+			assert.Contains(t, errs, tt.expectedErr)
+		})
+	}
+}
+
+func TestFlagValueSource(t *testing.T) {
+	mockSvc := newMockService(t)
+	mockSvc.On("Evaluate", mock.Anything, "default", mock.Anything, mock.Anything).Return(&evaluation.VariantEvaluationResponse{
+		Match:             true,
+		Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		VariantKey:        "5",
+		VariantAttachment: `{"limit": 10}`,
+	}, nil)
+
+	p := NewProvider(WithService(mockSvc), WithFlagValueSource("attachment-flag", Attachment("/limit")))
+
+	detail := p.IntEvaluation(context.Background(), "attachment-flag", 0, nil)
+	assert.Equal(t, int64(10), detail.Value)
+	assert.Equal(t, "5", detail.Variant)
+	assert.Equal(t, of.TargetingMatchReason, detail.Reason)
+
+	assert.Equal(t, int64(5), p.IntEvaluation(context.Background(), "variant-flag", 0, nil).Value)
+}
@@ -9,7 +9,10 @@ import (
 
 //go:generate mockery --name=Client --case=underscore --inpackage --filename=service_support.go --testonly --with-expecter --disable-version-string
 type Client interface {
+	GetNamespace(ctx context.Context, n *flipt.GetNamespaceRequest) (*flipt.Namespace, error)
 	GetFlag(ctx context.Context, c *flipt.GetFlagRequest) (*flipt.Flag, error)
+	ListFlags(ctx context.Context, l *flipt.ListFlagRequest) (*flipt.FlagList, error)
 	Variant(ctx context.Context, v *evaluation.EvaluationRequest) (*evaluation.VariantEvaluationResponse, error)
 	Boolean(ctx context.Context, v *evaluation.EvaluationRequest) (*evaluation.BooleanEvaluationResponse, error)
+	Batch(ctx context.Context, v *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error)
 }
@@ -0,0 +1,368 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ offlipt.Client = (*Client)(nil)
+
+const defaultRefreshInterval = 30 * time.Second
+
+// Client is a Flipt client which evaluates flags in-process using snapshots
+// of the namespaces it is asked about. Snapshots are loaded on first use and
+// refreshed in the background; when a refresh fails the previous snapshot
+// keeps being served.
+type Client struct {
+	loader          Loader
+	refreshInterval time.Duration
+	refreshListener func(err error)
+
+	mu        sync.RWMutex
+	snapshots map[string]*Snapshot
+	stop      context.CancelFunc
+	done      chan struct{}
+}
+
+// Option is a client option.
+type Option func(*Client)
+
+// WithRefreshInterval sets the interval at which snapshots are reloaded.
+// An interval of zero or less disables refreshing.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.refreshInterval = interval
+	}
+}
+
+// WithRefreshListener registers a listener which is called with the outcome
+// of every background refresh, as returned by Refresh.
+func WithRefreshListener(listener func(err error)) Option {
+	return func(c *Client) {
+		c.refreshListener = listener
+	}
+}
+
+// NewClient creates a new local evaluation client using the given loader.
+func NewClient(loader Loader, opts ...Option) *Client {
+	c := &Client{
+		loader:          loader,
+		refreshInterval: defaultRefreshInterval,
+		snapshots:       map[string]*Snapshot{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Close stops refreshing snapshots and discards them, so that they are
+// loaded again on next use.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.snapshots = map[string]*Snapshot{}
+	c.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-done
+	}
+
+	return nil
+}
+
+// NamespaceError is the failure to refresh the snapshot of a namespace.
+type NamespaceError struct {
+	Namespace string
+	Err       error
+}
+
+func (e *NamespaceError) Error() string {
+	return fmt.Sprintf("namespace %q: %v", e.Namespace, e.Err)
+}
+
+func (e *NamespaceError) Unwrap() error {
+	return e.Err
+}
+
+// StaleNamespaces returns the namespaces of the NamespaceErrors joined in err, as returned by Refresh.
+func StaleNamespaces(err error) []string {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	var namespaces []string
+
+	for _, err := range errs {
+		var nerr *NamespaceError
+		if errors.As(err, &nerr) {
+			namespaces = append(namespaces, nerr.Namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// Refresh reloads the snapshots of all namespaces which have been loaded so far.
+// Namespaces which fail to reload keep their previous snapshot; their failures
+// are returned as NamespaceErrors joined with errors.Join, ordered by namespace.
+func (c *Client) Refresh(ctx context.Context) error {
+	c.mu.RLock()
+	namespaces := make([]string, 0, len(c.snapshots))
+	for ns := range c.snapshots {
+		namespaces = append(namespaces, ns)
+	}
+	c.mu.RUnlock()
+
+	sort.Strings(namespaces)
+
+	var errs []error
+
+	for _, ns := range namespaces {
+		snap, err := c.loader.Load(ctx, ns)
+		if err != nil {
+			errs = append(errs, &NamespaceError{Namespace: ns, Err: err})
+
+			continue
+		}
+
+		c.mu.Lock()
+		c.snapshots[ns] = snap
+		c.mu.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
+
+// Snapshot returns the snapshot of a namespace which flags are evaluated against,
+// loading it on first use.
+func (c *Client) Snapshot(ctx context.Context, namespaceKey string) (*Snapshot, error) {
+	return c.snapshot(ctx, namespaceKey)
+}
+
+func (c *Client) snapshot(ctx context.Context, namespaceKey string) (*Snapshot, error) {
+	c.mu.RLock()
+	snap, ok := c.snapshots[namespaceKey]
+	c.mu.RUnlock()
+
+	if ok {
+		return snap, nil
+	}
+
+	snap, err := c.loader.Load(ctx, namespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.snapshots[namespaceKey] = snap
+
+	if c.stop == nil && c.refreshInterval > 0 {
+		rctx, cancel := context.WithCancel(context.Background())
+		c.stop, c.done = cancel, make(chan struct{})
+
+		go c.refresh(rctx, c.done)
+	}
+
+	return snap, nil
+}
+
+func (c *Client) refresh(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// failures keep the previous snapshots, which are retried on the next tick.
+			err := c.Refresh(ctx)
+			if ctx.Err() == nil && c.refreshListener != nil {
+				c.refreshListener(err)
+			}
+		}
+	}
+}
+
+// GetNamespace returns the namespace of the snapshot for the given key.
+func (c *Client) GetNamespace(ctx context.Context, r *flipt.GetNamespaceRequest) (*flipt.Namespace, error) {
+	snap, err := c.snapshot(ctx, r.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return snap.Namespace, nil
+}
+
+// GetFlag returns a flag from the snapshot of its namespace.
+func (c *Client) GetFlag(ctx context.Context, r *flipt.GetFlagRequest) (*flipt.Flag, error) {
+	snap, err := c.snapshot(ctx, r.NamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	flag, ok := snap.Flags[r.Key]
+	if !ok {
+		return nil, flagNotFound(r.NamespaceKey, r.Key)
+	}
+
+	return flag, nil
+}
+
+// ListFlags returns all flags from the snapshot of a namespace in a single page, ordered by key.
+func (c *Client) ListFlags(ctx context.Context, r *flipt.ListFlagRequest) (*flipt.FlagList, error) {
+	snap, err := c.snapshot(ctx, r.NamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]*flipt.Flag, 0, len(snap.Flags))
+	for _, flag := range snap.Flags {
+		flags = append(flags, flag)
+	}
+
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+
+	return &flipt.FlagList{
+		Flags:      flags,
+		TotalCount: int32(len(flags)),
+	}, nil
+}
+
+// Variant evaluates a variant flag.
+func (c *Client) Variant(ctx context.Context, r *evaluation.EvaluationRequest) (*evaluation.VariantEvaluationResponse, error) {
+	start := time.Now()
+
+	snap, err := c.snapshot(ctx, r.NamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	flag, ok := snap.Flags[r.FlagKey]
+	if !ok {
+		return nil, flagNotFound(r.NamespaceKey, r.FlagKey)
+	}
+
+	resp, err := variant(flag, snap.Rules[r.FlagKey], r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.RequestId = r.RequestId
+	resp.Timestamp = timestamppb.New(time.Now().UTC())
+	resp.RequestDurationMillis = float64(time.Since(start)) / float64(time.Millisecond)
+
+	return resp, nil
+}
+
+// Boolean evaluates a boolean flag.
+func (c *Client) Boolean(ctx context.Context, r *evaluation.EvaluationRequest) (*evaluation.BooleanEvaluationResponse, error) {
+	start := time.Now()
+
+	snap, err := c.snapshot(ctx, r.NamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	flag, ok := snap.Flags[r.FlagKey]
+	if !ok {
+		return nil, flagNotFound(r.NamespaceKey, r.FlagKey)
+	}
+
+	resp, err := boolean(flag, snap.Rollouts[r.FlagKey], r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.RequestId = r.RequestId
+	resp.Timestamp = timestamppb.New(time.Now().UTC())
+	resp.RequestDurationMillis = float64(time.Since(start)) / float64(time.Millisecond)
+
+	return resp, nil
+}
+
+// Batch evaluates many flags at once. Each flag is evaluated according to its
+// type and flags which don't exist are reported as error responses, as Flipt
+// does; any other failure fails the whole batch.
+func (c *Client) Batch(ctx context.Context, r *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error) {
+	start := time.Now()
+
+	responses := make([]*evaluation.EvaluationResponse, 0, len(r.Requests))
+
+	for _, req := range r.Requests {
+		resp, err := c.evaluate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, resp)
+	}
+
+	return &evaluation.BatchEvaluationResponse{
+		RequestId:             r.RequestId,
+		Responses:             responses,
+		RequestDurationMillis: float64(time.Since(start)) / float64(time.Millisecond),
+	}, nil
+}
+
+func (c *Client) evaluate(ctx context.Context, r *evaluation.EvaluationRequest) (*evaluation.EvaluationResponse, error) {
+	snap, err := c.snapshot(ctx, r.NamespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	flag, ok := snap.Flags[r.FlagKey]
+	if !ok {
+		return &evaluation.EvaluationResponse{
+			Type: evaluation.EvaluationResponseType_ERROR_EVALUATION_RESPONSE_TYPE,
+			Response: &evaluation.EvaluationResponse_ErrorResponse{
+				ErrorResponse: &evaluation.ErrorEvaluationResponse{
+					FlagKey:      r.FlagKey,
+					NamespaceKey: r.NamespaceKey,
+					Reason:       evaluation.ErrorEvaluationReason_NOT_FOUND_ERROR_EVALUATION_REASON,
+				},
+			},
+		}, nil
+	}
+
+	if flag.Type == flipt.FlagType_BOOLEAN_FLAG_TYPE {
+		resp, err := c.Boolean(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		return &evaluation.EvaluationResponse{
+			Type:     evaluation.EvaluationResponseType_BOOLEAN_EVALUATION_RESPONSE_TYPE,
+			Response: &evaluation.EvaluationResponse_BooleanResponse{BooleanResponse: resp},
+		}, nil
+	}
+
+	resp, err := c.Variant(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluation.EvaluationResponse{
+		Type:     evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+		Response: &evaluation.EvaluationResponse_VariantResponse{VariantResponse: resp},
+	}, nil
+}
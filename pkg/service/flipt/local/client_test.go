@@ -0,0 +1,274 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeLister struct {
+	flags    []*flipt.Flag
+	segments []*flipt.Segment
+	rules    map[string][]*flipt.Rule
+	rollouts map[string][]*flipt.Rollout
+}
+
+func (f *fakeLister) GetNamespace(_ context.Context, n *flipt.GetNamespaceRequest) (*flipt.Namespace, error) {
+	return &flipt.Namespace{Key: n.Key}, nil
+}
+
+func (f *fakeLister) ListFlags(_ context.Context, l *flipt.ListFlagRequest) (*flipt.FlagList, error) {
+	// serve one flag per page to exercise paging.
+	if l.PageToken == "" {
+		return &flipt.FlagList{Flags: f.flags[:1], NextPageToken: "next"}, nil
+	}
+
+	return &flipt.FlagList{Flags: f.flags[1:]}, nil
+}
+
+func (f *fakeLister) ListSegments(_ context.Context, _ *flipt.ListSegmentRequest) (*flipt.SegmentList, error) {
+	return &flipt.SegmentList{Segments: f.segments}, nil
+}
+
+func (f *fakeLister) ListRules(_ context.Context, l *flipt.ListRuleRequest) (*flipt.RuleList, error) {
+	return &flipt.RuleList{Rules: f.rules[l.FlagKey]}, nil
+}
+
+func (f *fakeLister) ListRollouts(_ context.Context, l *flipt.ListRolloutRequest) (*flipt.RolloutList, error) {
+	return &flipt.RolloutList{Rules: f.rollouts[l.FlagKey]}, nil
+}
+
+func newFakeLister() *fakeLister {
+	return &fakeLister{
+		flags: []*flipt.Flag{
+			{
+				Key:     "variant-flag",
+				Type:    flipt.FlagType_VARIANT_FLAG_TYPE,
+				Enabled: true,
+				Variants: []*flipt.Variant{
+					{Id: "v1", Key: "a", Attachment: `{"a":1}`},
+				},
+			},
+			{
+				Key:  "boolean-flag",
+				Type: flipt.FlagType_BOOLEAN_FLAG_TYPE,
+			},
+		},
+		segments: []*flipt.Segment{
+			{
+				Key:       "premium",
+				MatchType: flipt.MatchType_ALL_MATCH_TYPE,
+				Constraints: []*flipt.Constraint{
+					{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Property: "plan", Operator: "eq", Value: "premium"},
+				},
+			},
+		},
+		rules: map[string][]*flipt.Rule{
+			"variant-flag": {
+				{
+					Rank:          1,
+					SegmentKey:    "premium",
+					Distributions: []*flipt.Distribution{{VariantId: "v1", Rollout: 100}},
+				},
+			},
+		},
+		rollouts: map[string][]*flipt.Rollout{
+			"boolean-flag": {
+				{
+					Rank: 1,
+					Rule: &flipt.Rollout_Segment{Segment: &flipt.RolloutSegment{SegmentKey: "premium", Value: true}},
+				},
+			},
+		},
+	}
+}
+
+func TestAPILoader(t *testing.T) {
+	snap, err := NewAPILoader(newFakeLister()).Load(context.Background(), "default")
+	require.NoError(t, err)
+
+	assert.Equal(t, "default", snap.Namespace.Key)
+	assert.Len(t, snap.Flags, 2)
+	assert.Equal(t, []*Rule{{
+		Rank:          1,
+		Segments:      []*Segment{{Key: "premium", MatchType: flipt.MatchType_ALL_MATCH_TYPE, Constraints: []*Constraint{{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Property: "plan", Operator: "eq", Value: "premium"}}}},
+		Distributions: []*Distribution{{Rollout: 100, VariantKey: "a", VariantAttachment: `{"a":1}`}},
+	}}, snap.Rules["variant-flag"])
+	require.Len(t, snap.Rollouts["boolean-flag"], 1)
+	assert.True(t, snap.Rollouts["boolean-flag"][0].Segment.Value)
+
+	lister := newFakeLister()
+	lister.rules["variant-flag"][0].SegmentKey = "unknown"
+
+	_, err = NewAPILoader(lister).Load(context.Background(), "default")
+	assert.EqualError(t, err, `flag "variant-flag": segment "unknown" not found`)
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient(NewAPILoader(newFakeLister()), WithRefreshInterval(0))
+	defer client.Close()
+
+	ctx := context.Background()
+
+	variant, err := client.Variant(ctx, &evaluation.EvaluationRequest{
+		RequestId:    "request",
+		NamespaceKey: "default",
+		FlagKey:      "variant-flag",
+		EntityId:     "entity",
+		Context:      map[string]string{"plan": "premium"},
+	})
+	require.NoError(t, err)
+	assert.True(t, variant.Match)
+	assert.Equal(t, "a", variant.VariantKey)
+	assert.Equal(t, `{"a":1}`, variant.VariantAttachment)
+	assert.Equal(t, []string{"premium"}, variant.SegmentKeys)
+	assert.Equal(t, "request", variant.RequestId)
+	assert.NotNil(t, variant.Timestamp)
+
+	boolean, err := client.Boolean(ctx, &evaluation.EvaluationRequest{
+		NamespaceKey: "default",
+		FlagKey:      "boolean-flag",
+		EntityId:     "entity",
+		Context:      map[string]string{"plan": "free"},
+	})
+	require.NoError(t, err)
+	assert.False(t, boolean.Enabled)
+	assert.Equal(t, evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON, boolean.Reason)
+
+	_, err = client.Variant(ctx, &evaluation.EvaluationRequest{NamespaceKey: "default", FlagKey: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	flags, err := client.ListFlags(ctx, &flipt.ListFlagRequest{NamespaceKey: "default"})
+	require.NoError(t, err)
+	require.Len(t, flags.Flags, 2)
+	assert.Equal(t, "boolean-flag", flags.Flags[0].Key)
+	assert.Equal(t, "variant-flag", flags.Flags[1].Key)
+}
+
+func TestClient_Batch(t *testing.T) {
+	client := NewClient(NewAPILoader(newFakeLister()), WithRefreshInterval(0))
+	defer client.Close()
+
+	ec := map[string]string{"plan": "premium"}
+
+	resp, err := client.Batch(context.Background(), &evaluation.BatchEvaluationRequest{
+		RequestId: "request",
+		Requests: []*evaluation.EvaluationRequest{
+			{RequestId: "request", NamespaceKey: "default", FlagKey: "variant-flag", EntityId: "entity", Context: ec},
+			{RequestId: "request", NamespaceKey: "default", FlagKey: "boolean-flag", EntityId: "entity", Context: ec},
+			{RequestId: "request", NamespaceKey: "default", FlagKey: "missing", EntityId: "entity", Context: ec},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "request", resp.RequestId)
+	require.Len(t, resp.Responses, 3)
+
+	assert.Equal(t, evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE, resp.Responses[0].Type)
+	assert.Equal(t, "a", resp.Responses[0].GetVariantResponse().VariantKey)
+
+	assert.Equal(t, evaluation.EvaluationResponseType_BOOLEAN_EVALUATION_RESPONSE_TYPE, resp.Responses[1].Type)
+	assert.NotNil(t, resp.Responses[1].GetBooleanResponse())
+
+	assert.Equal(t, evaluation.EvaluationResponseType_ERROR_EVALUATION_RESPONSE_TYPE, resp.Responses[2].Type)
+	assert.Equal(t, &evaluation.ErrorEvaluationResponse{
+		FlagKey:      "missing",
+		NamespaceKey: "default",
+		Reason:       evaluation.ErrorEvaluationReason_NOT_FOUND_ERROR_EVALUATION_REASON,
+	}, resp.Responses[2].GetErrorResponse())
+
+	failing := NewClient(LoaderFunc(func(context.Context, string) (*Snapshot, error) {
+		return nil, errors.New("unavailable")
+	}))
+
+	_, err = failing.Batch(context.Background(), &evaluation.BatchEvaluationRequest{
+		Requests: []*evaluation.EvaluationRequest{{NamespaceKey: "default", FlagKey: "variant-flag"}},
+	})
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestClient_Refresh(t *testing.T) {
+	var (
+		loads  int
+		failed = errors.New("unavailable")
+	)
+
+	client := NewClient(LoaderFunc(func(_ context.Context, namespaceKey string) (*Snapshot, error) {
+		loads++
+
+		if loads > 1 {
+			return nil, failed
+		}
+
+		return &Snapshot{
+			Namespace: &flipt.Namespace{Key: namespaceKey},
+			Flags: map[string]*flipt.Flag{
+				"boolean-flag": {Key: "boolean-flag", Type: flipt.FlagType_BOOLEAN_FLAG_TYPE, Enabled: true},
+			},
+		}, nil
+	}), WithRefreshInterval(0))
+	defer client.Close()
+
+	ctx := context.Background()
+
+	_, err := client.GetNamespace(ctx, &flipt.GetNamespaceRequest{Key: "default"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, client.Refresh(ctx), failed)
+
+	// the previous snapshot keeps being served when a refresh fails.
+	flag, err := client.GetFlag(ctx, &flipt.GetFlagRequest{NamespaceKey: "default", Key: "boolean-flag"})
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	assert.Equal(t, 2, loads)
+}
+
+func TestClient_RefreshNamespaces(t *testing.T) {
+	var (
+		refreshing bool
+		failed     = errors.New("unavailable")
+	)
+
+	client := NewClient(LoaderFunc(func(_ context.Context, namespaceKey string) (*Snapshot, error) {
+		if refreshing && namespaceKey != "b" {
+			return nil, failed
+		}
+
+		return &Snapshot{
+			Namespace: &flipt.Namespace{Key: namespaceKey},
+			Flags: map[string]*flipt.Flag{
+				"boolean-flag": {Key: "boolean-flag", Type: flipt.FlagType_BOOLEAN_FLAG_TYPE, Enabled: refreshing},
+			},
+		}, nil
+	}), WithRefreshInterval(0))
+	defer client.Close()
+
+	ctx := context.Background()
+
+	for _, ns := range []string{"c", "b", "a"} {
+		_, err := client.GetNamespace(ctx, &flipt.GetNamespaceRequest{Key: ns})
+		require.NoError(t, err)
+	}
+
+	refreshing = true
+
+	// failing namespaces don't prevent the others from refreshing.
+	err := client.Refresh(ctx)
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, []string{"a", "c"}, StaleNamespaces(err))
+	assert.EqualError(t, err, "namespace \"a\": unavailable\nnamespace \"c\": unavailable")
+
+	flag, err := client.GetFlag(ctx, &flipt.GetFlagRequest{NamespaceKey: "b", Key: "boolean-flag"})
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+
+	flag, err = client.GetFlag(ctx, &flipt.GetFlagRequest{NamespaceKey: "a", Key: "boolean-flag"})
+	require.NoError(t, err)
+	assert.False(t, flag.Enabled)
+}
@@ -0,0 +1,434 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The evaluation in this file mirrors the evaluator of the Flipt server so that
+// local results are identical to those returned by the Flipt API.
+
+const (
+	// totalBucketNum is the number of buckets entities are hashed into for variant distributions.
+	totalBucketNum uint = 1000
+
+	// percentMultiplier is the multiplier between a percentage and totalBucketNum.
+	percentMultiplier float32 = float32(totalBucketNum) / 100
+)
+
+// Constraint operators supported by Flipt.
+const (
+	opEQ         = "eq"
+	opNEQ        = "neq"
+	opLT         = "lt"
+	opLTE        = "lte"
+	opGT         = "gt"
+	opGTE        = "gte"
+	opEmpty      = "empty"
+	opNotEmpty   = "notempty"
+	opTrue       = "true"
+	opFalse      = "false"
+	opPresent    = "present"
+	opNotPresent = "notpresent"
+	opPrefix     = "prefix"
+	opSuffix     = "suffix"
+	opIsOneOf    = "isoneof"
+	opIsNotOneOf = "isnotoneof"
+)
+
+// variant evaluates a variant flag against its rules.
+func variant(flag *flipt.Flag, rules []*Rule, r *evaluation.EvaluationRequest) (*evaluation.VariantEvaluationResponse, error) {
+	if flag.Type != flipt.FlagType_VARIANT_FLAG_TYPE {
+		return nil, status.Errorf(codes.InvalidArgument, "flag type %s invalid", flag.Type)
+	}
+
+	resp := &evaluation.VariantEvaluationResponse{}
+
+	if !flag.Enabled {
+		resp.Reason = evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON
+
+		return resp, nil
+	}
+
+	for _, rule := range rules {
+		segmentKeys, matched, err := matchSegments(r.Context, rule.Segments, rule.SegmentOperator)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matched {
+			continue
+		}
+
+		if len(segmentKeys) > 0 {
+			resp.SegmentKeys = segmentKeys
+		}
+
+		var (
+			validDistributions []*Distribution
+			buckets            []int
+		)
+
+		for _, d := range rule.Distributions {
+			// don't include 0% rollouts
+			if d.Rollout <= 0 {
+				continue
+			}
+
+			validDistributions = append(validDistributions, d)
+
+			bucket := int(d.Rollout * percentMultiplier)
+			if len(buckets) > 0 {
+				bucket += buckets[len(buckets)-1]
+			}
+
+			buckets = append(buckets, bucket)
+		}
+
+		// a rule without distributions matches without a variant.
+		if len(validDistributions) == 0 {
+			resp.Match = true
+			resp.Reason = evaluation.EvaluationReason_MATCH_EVALUATION_REASON
+
+			return resp, nil
+		}
+
+		var (
+			bucket = crc32Num(r.EntityId, r.FlagKey)
+			index  = sort.SearchInts(buckets, int(bucket)+1)
+		)
+
+		// the entity falls outside of every distribution.
+		if index == len(validDistributions) {
+			return resp, nil
+		}
+
+		d := validDistributions[index]
+
+		resp.Match = true
+		resp.Reason = evaluation.EvaluationReason_MATCH_EVALUATION_REASON
+		resp.VariantKey = d.VariantKey
+		resp.VariantAttachment = d.VariantAttachment
+
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+// boolean evaluates a boolean flag against its rollouts.
+func boolean(flag *flipt.Flag, rollouts []*Rollout, r *evaluation.EvaluationRequest) (*evaluation.BooleanEvaluationResponse, error) {
+	if flag.Type != flipt.FlagType_BOOLEAN_FLAG_TYPE {
+		return nil, status.Errorf(codes.InvalidArgument, "flag type %s invalid", flag.Type)
+	}
+
+	resp := &evaluation.BooleanEvaluationResponse{}
+
+	for _, rollout := range rollouts {
+		switch {
+		case rollout.Threshold != nil:
+			// consistent hashing based on the entity id and flag key.
+			hash := crc32.ChecksumIEEE([]byte(r.EntityId + r.FlagKey))
+			normalizedValue := float32(int(hash) % 100)
+
+			if normalizedValue < rollout.Threshold.Percentage {
+				resp.Enabled = rollout.Threshold.Value
+				resp.Reason = evaluation.EvaluationReason_MATCH_EVALUATION_REASON
+
+				return resp, nil
+			}
+		case rollout.Segment != nil:
+			_, matched, err := matchSegments(r.Context, rollout.Segment.Segments, rollout.Segment.SegmentOperator)
+			if err != nil {
+				return nil, err
+			}
+
+			if !matched {
+				continue
+			}
+
+			resp.Enabled = rollout.Segment.Value
+			resp.Reason = evaluation.EvaluationReason_MATCH_EVALUATION_REASON
+
+			return resp, nil
+		}
+	}
+
+	// no rollout matched, so the flag's enabled state is the value.
+	resp.Enabled = flag.Enabled
+	resp.Reason = evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON
+
+	return resp, nil
+}
+
+func crc32Num(entityID string, salt string) uint {
+	return uint(crc32.ChecksumIEEE([]byte(salt+entityID))) % totalBucketNum
+}
+
+// matchSegments returns the keys of the matching segments and whether they
+// satisfy the segment operator.
+func matchSegments(evalCtx map[string]string, segments []*Segment, operator flipt.SegmentOperator) ([]string, bool, error) {
+	var segmentKeys []string
+
+	for _, segment := range segments {
+		matched, err := matchConstraints(evalCtx, segment.Constraints, segment.MatchType)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if matched {
+			segmentKeys = append(segmentKeys, segment.Key)
+		}
+	}
+
+	switch operator {
+	case flipt.SegmentOperator_AND_SEGMENT_OPERATOR:
+		return segmentKeys, len(segmentKeys) == len(segments), nil
+	default:
+		return segmentKeys, len(segmentKeys) > 0, nil
+	}
+}
+
+func matchConstraints(evalCtx map[string]string, constraints []*Constraint, matchType flipt.MatchType) (bool, error) {
+	constraintMatches := 0
+
+	for _, c := range constraints {
+		v := evalCtx[c.Property]
+
+		var (
+			match bool
+			err   error
+		)
+
+		switch c.Type {
+		case flipt.ComparisonType_STRING_COMPARISON_TYPE:
+			match = matchesString(c, v)
+		case flipt.ComparisonType_NUMBER_COMPARISON_TYPE:
+			match, err = matchesNumber(c, v)
+		case flipt.ComparisonType_BOOLEAN_COMPARISON_TYPE:
+			match, err = matchesBool(c, v)
+		case flipt.ComparisonType_DATETIME_COMPARISON_TYPE:
+			match, err = matchesDateTime(c, v)
+		default:
+			return false, status.Error(codes.InvalidArgument, "unknown constraint type")
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		if match {
+			constraintMatches++
+
+			if matchType == flipt.MatchType_ANY_MATCH_TYPE {
+				break
+			}
+
+			continue
+		}
+
+		if matchType == flipt.MatchType_ALL_MATCH_TYPE {
+			break
+		}
+	}
+
+	switch matchType {
+	case flipt.MatchType_ALL_MATCH_TYPE:
+		return len(constraints) == constraintMatches, nil
+	case flipt.MatchType_ANY_MATCH_TYPE:
+		return len(constraints) == 0 || constraintMatches > 0, nil
+	default:
+		return false, nil
+	}
+}
+
+func matchesString(c *Constraint, v string) bool {
+	switch c.Operator {
+	case opEmpty:
+		return len(strings.TrimSpace(v)) == 0
+	case opNotEmpty:
+		return len(strings.TrimSpace(v)) != 0
+	}
+
+	if v == "" {
+		return false
+	}
+
+	value := c.Value
+
+	switch c.Operator {
+	case opEQ:
+		return value == v
+	case opNEQ:
+		return value != v
+	case opPrefix:
+		return strings.HasPrefix(strings.TrimSpace(v), value)
+	case opSuffix:
+		return strings.HasSuffix(strings.TrimSpace(v), value)
+	case opIsOneOf, opIsNotOneOf:
+		var values []string
+		if err := json.Unmarshal([]byte(value), &values); err != nil {
+			return false
+		}
+
+		return contains(values, v) == (c.Operator == opIsOneOf)
+	}
+
+	return false
+}
+
+func matchesNumber(c *Constraint, v string) (bool, error) {
+	switch c.Operator {
+	case opNotPresent:
+		return len(strings.TrimSpace(v)) == 0, nil
+	case opPresent:
+		return len(strings.TrimSpace(v)) != 0, nil
+	}
+
+	// can't parse an empty string
+	if v == "" {
+		return false, nil
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "parsing number from %q", v)
+	}
+
+	if c.Operator == opIsOneOf || c.Operator == opIsNotOneOf {
+		var values []float64
+		if err := json.Unmarshal([]byte(c.Value), &values); err != nil {
+			return false, status.Errorf(codes.InvalidArgument, "Invalid value for constraint %q", c.Value)
+		}
+
+		return contains(values, n) == (c.Operator == opIsOneOf), nil
+	}
+
+	value, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "parsing number from %q", c.Value)
+	}
+
+	switch c.Operator {
+	case opEQ:
+		return value == n, nil
+	case opNEQ:
+		return value != n, nil
+	case opLT:
+		return n < value, nil
+	case opLTE:
+		return n <= value, nil
+	case opGT:
+		return n > value, nil
+	case opGTE:
+		return n >= value, nil
+	}
+
+	return false, nil
+}
+
+func matchesBool(c *Constraint, v string) (bool, error) {
+	switch c.Operator {
+	case opNotPresent:
+		return len(strings.TrimSpace(v)) == 0, nil
+	case opPresent:
+		return len(strings.TrimSpace(v)) != 0, nil
+	}
+
+	// can't parse an empty string
+	if v == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "parsing boolean from %q", v)
+	}
+
+	switch c.Operator {
+	case opTrue:
+		return value, nil
+	case opFalse:
+		return !value, nil
+	}
+
+	return false, nil
+}
+
+func matchesDateTime(c *Constraint, v string) (bool, error) {
+	switch c.Operator {
+	case opNotPresent:
+		return len(strings.TrimSpace(v)) == 0, nil
+	case opPresent:
+		return len(strings.TrimSpace(v)) != 0, nil
+	}
+
+	// can't parse an empty string
+	if v == "" {
+		return false, nil
+	}
+
+	d, err := tryParseDateTime(v)
+	if err != nil {
+		return false, err
+	}
+
+	value, err := tryParseDateTime(c.Value)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.Operator {
+	case opEQ:
+		return d.Equal(value), nil
+	case opNEQ:
+		return !d.Equal(value), nil
+	case opLT:
+		return d.Before(value), nil
+	case opLTE:
+		return d.Before(value) || d.Equal(value), nil
+	case opGT:
+		return d.After(value), nil
+	case opGTE:
+		return d.After(value) || d.Equal(value), nil
+	}
+
+	return false, nil
+}
+
+func tryParseDateTime(v string) (time.Time, error) {
+	if d, err := time.Parse(time.RFC3339, v); err == nil {
+		return d.UTC(), nil
+	}
+
+	if d, err := time.Parse(time.DateOnly, v); err == nil {
+		return d.UTC(), nil
+	}
+
+	return time.Time{}, status.Errorf(codes.InvalidArgument, "parsing datetime from %q", v)
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// flagNotFound returns the error the Flipt API returns for a missing flag.
+func flagNotFound(namespaceKey, flagKey string) error {
+	return status.Error(codes.NotFound, fmt.Sprintf("flag \"%s/%s\" not found", namespaceKey, flagKey))
+}
@@ -0,0 +1,280 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var premiumSegment = &Segment{
+	Key:       "premium",
+	MatchType: flipt.MatchType_ALL_MATCH_TYPE,
+	Constraints: []*Constraint{
+		{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Property: "plan", Operator: opEQ, Value: "premium"},
+	},
+}
+
+func TestVariant(t *testing.T) {
+	flag := &flipt.Flag{Key: "flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE, Enabled: true}
+
+	tests := []struct {
+		name         string
+		flag         *flipt.Flag
+		rules        []*Rule
+		entityID     string
+		context      map[string]string
+		expectedResp *evaluation.VariantEvaluationResponse
+	}{
+		{
+			name: "first bucket",
+			flag: flag,
+			rules: []*Rule{{
+				Segments: []*Segment{premiumSegment},
+				Distributions: []*Distribution{
+					{Rollout: 50, VariantKey: "a", VariantAttachment: `{"a":1}`},
+					{Rollout: 50, VariantKey: "b"},
+				},
+			}},
+			// crc32("flag3") % 1000 == 354
+			entityID: "3",
+			context:  map[string]string{"plan": "premium"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				Match:             true,
+				Reason:            evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				SegmentKeys:       []string{"premium"},
+				VariantKey:        "a",
+				VariantAttachment: `{"a":1}`,
+			},
+		},
+		{
+			name: "second bucket",
+			flag: flag,
+			rules: []*Rule{{
+				Segments: []*Segment{premiumSegment},
+				Distributions: []*Distribution{
+					{Rollout: 50, VariantKey: "a"},
+					{Rollout: 50, VariantKey: "b"},
+				},
+			}},
+			// crc32("flag1") % 1000 == 830
+			entityID: "1",
+			context:  map[string]string{"plan": "premium"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				Match:       true,
+				Reason:      evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				SegmentKeys: []string{"premium"},
+				VariantKey:  "b",
+			},
+		},
+		{
+			name: "outside of distributions",
+			flag: flag,
+			rules: []*Rule{{
+				Segments: []*Segment{premiumSegment},
+				Distributions: []*Distribution{
+					{Rollout: 30, VariantKey: "a"},
+					{Rollout: 30, VariantKey: "b"},
+				},
+			}},
+			// crc32("flag2") % 1000 == 628
+			entityID: "2",
+			context:  map[string]string{"plan": "premium"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				SegmentKeys: []string{"premium"},
+			},
+		},
+		{
+			name: "match without distributions",
+			flag: flag,
+			rules: []*Rule{{
+				Segments: []*Segment{premiumSegment},
+			}},
+			entityID: "1",
+			context:  map[string]string{"plan": "premium"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				Match:       true,
+				Reason:      evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				SegmentKeys: []string{"premium"},
+			},
+		},
+		{
+			name: "no match",
+			flag: flag,
+			rules: []*Rule{{
+				Segments:      []*Segment{premiumSegment},
+				Distributions: []*Distribution{{Rollout: 100, VariantKey: "a"}},
+			}},
+			entityID:     "1",
+			context:      map[string]string{"plan": "free"},
+			expectedResp: &evaluation.VariantEvaluationResponse{},
+		},
+		{
+			name: "and segment operator",
+			flag: flag,
+			rules: []*Rule{{
+				SegmentOperator: flipt.SegmentOperator_AND_SEGMENT_OPERATOR,
+				Segments: []*Segment{premiumSegment, {
+					Key:       "beta",
+					MatchType: flipt.MatchType_ANY_MATCH_TYPE,
+					Constraints: []*Constraint{
+						{Type: flipt.ComparisonType_BOOLEAN_COMPARISON_TYPE, Property: "beta", Operator: opTrue},
+					},
+				}},
+				Distributions: []*Distribution{{Rollout: 100, VariantKey: "a"}},
+			}},
+			entityID: "1",
+			context:  map[string]string{"plan": "premium", "beta": "true"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				Match:       true,
+				Reason:      evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+				SegmentKeys: []string{"premium", "beta"},
+				VariantKey:  "a",
+			},
+		},
+		{
+			name: "disabled",
+			flag: &flipt.Flag{Key: "flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE},
+			rules: []*Rule{{
+				Segments:      []*Segment{premiumSegment},
+				Distributions: []*Distribution{{Rollout: 100, VariantKey: "a"}},
+			}},
+			entityID: "1",
+			context:  map[string]string{"plan": "premium"},
+			expectedResp: &evaluation.VariantEvaluationResponse{
+				Reason: evaluation.EvaluationReason_FLAG_DISABLED_EVALUATION_REASON,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := variant(tt.flag, tt.rules, &evaluation.EvaluationRequest{
+				FlagKey:  "flag",
+				EntityId: tt.entityID,
+				Context:  tt.context,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedResp, resp)
+		})
+	}
+
+	_, err := variant(&flipt.Flag{Key: "flag", Type: flipt.FlagType_BOOLEAN_FLAG_TYPE}, nil, &evaluation.EvaluationRequest{FlagKey: "flag"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestBoolean(t *testing.T) {
+	flag := &flipt.Flag{Key: "flag", Type: flipt.FlagType_BOOLEAN_FLAG_TYPE}
+
+	rollouts := []*Rollout{
+		{Rank: 1, Segment: &RolloutSegment{Segments: []*Segment{premiumSegment}, Value: true}},
+		{Rank: 2, Threshold: &Threshold{Percentage: 50, Value: true}},
+	}
+
+	tests := []struct {
+		name           string
+		entityID       string
+		context        map[string]string
+		expectedValue  bool
+		expectedReason evaluation.EvaluationReason
+	}{
+		{
+			name:           "segment match",
+			entityID:       "3",
+			context:        map[string]string{"plan": "premium"},
+			expectedValue:  true,
+			expectedReason: evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		},
+		{
+			// crc32("7flag") % 100 == 49
+			name:           "within threshold",
+			entityID:       "7",
+			expectedValue:  true,
+			expectedReason: evaluation.EvaluationReason_MATCH_EVALUATION_REASON,
+		},
+		{
+			// crc32("3flag") % 100 == 73
+			name:           "outside threshold",
+			entityID:       "3",
+			expectedValue:  false,
+			expectedReason: evaluation.EvaluationReason_DEFAULT_EVALUATION_REASON,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := boolean(flag, rollouts, &evaluation.EvaluationRequest{
+				FlagKey:  "flag",
+				EntityId: tt.entityID,
+				Context:  tt.context,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, resp.Enabled)
+			assert.Equal(t, tt.expectedReason, resp.Reason)
+		})
+	}
+
+	_, err := boolean(&flipt.Flag{Key: "flag", Type: flipt.FlagType_VARIANT_FLAG_TYPE}, nil, &evaluation.EvaluationRequest{FlagKey: "flag"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMatchConstraints(t *testing.T) {
+	tests := []struct {
+		name          string
+		constraint    *Constraint
+		value         string
+		expectedMatch bool
+		expectedErr   bool
+	}{
+		{name: "string eq", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opEQ, Value: "foo"}, value: "foo", expectedMatch: true},
+		{name: "string neq", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opNEQ, Value: "foo"}, value: "bar", expectedMatch: true},
+		{name: "string empty", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opEmpty}, value: " ", expectedMatch: true},
+		{name: "string prefix", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opPrefix, Value: "fo"}, value: "foo", expectedMatch: true},
+		{name: "string suffix", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opSuffix, Value: "oo"}, value: "foo", expectedMatch: true},
+		{name: "string is one of", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opIsOneOf, Value: `["foo","bar"]`}, value: "bar", expectedMatch: true},
+		{name: "string is not one of", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opIsNotOneOf, Value: `["foo","bar"]`}, value: "bar"},
+		{name: "string missing", constraint: &Constraint{Type: flipt.ComparisonType_STRING_COMPARISON_TYPE, Operator: opNEQ, Value: "foo"}},
+		{name: "number lt", constraint: &Constraint{Type: flipt.ComparisonType_NUMBER_COMPARISON_TYPE, Operator: opLT, Value: "10"}, value: "9.5", expectedMatch: true},
+		{name: "number gte", constraint: &Constraint{Type: flipt.ComparisonType_NUMBER_COMPARISON_TYPE, Operator: opGTE, Value: "10"}, value: "9.5"},
+		{name: "number is one of", constraint: &Constraint{Type: flipt.ComparisonType_NUMBER_COMPARISON_TYPE, Operator: opIsOneOf, Value: `[1,2]`}, value: "2", expectedMatch: true},
+		{name: "number present", constraint: &Constraint{Type: flipt.ComparisonType_NUMBER_COMPARISON_TYPE, Operator: opPresent}, value: "1", expectedMatch: true},
+		{name: "number invalid", constraint: &Constraint{Type: flipt.ComparisonType_NUMBER_COMPARISON_TYPE, Operator: opEQ, Value: "1"}, value: "one", expectedErr: true},
+		{name: "boolean false", constraint: &Constraint{Type: flipt.ComparisonType_BOOLEAN_COMPARISON_TYPE, Operator: opFalse}, value: "false", expectedMatch: true},
+		{name: "boolean not present", constraint: &Constraint{Type: flipt.ComparisonType_BOOLEAN_COMPARISON_TYPE, Operator: opNotPresent}, expectedMatch: true},
+		{name: "boolean invalid", constraint: &Constraint{Type: flipt.ComparisonType_BOOLEAN_COMPARISON_TYPE, Operator: opTrue}, value: "yes", expectedErr: true},
+		{name: "datetime gt", constraint: &Constraint{Type: flipt.ComparisonType_DATETIME_COMPARISON_TYPE, Operator: opGT, Value: "2023-10-01"}, value: "2023-10-01T12:00:00Z", expectedMatch: true},
+		{name: "datetime eq", constraint: &Constraint{Type: flipt.ComparisonType_DATETIME_COMPARISON_TYPE, Operator: opEQ, Value: "2023-10-01T00:00:00Z"}, value: "2023-10-01", expectedMatch: true},
+		{name: "datetime invalid", constraint: &Constraint{Type: flipt.ComparisonType_DATETIME_COMPARISON_TYPE, Operator: opEQ, Value: "2023-10-01"}, value: "yesterday", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			tt.constraint.Property = "prop"
+
+			evalCtx := map[string]string{}
+			if tt.value != "" {
+				evalCtx["prop"] = tt.value
+			}
+
+			match, err := matchConstraints(evalCtx, []*Constraint{tt.constraint}, flipt.MatchType_ALL_MATCH_TYPE)
+			if tt.expectedErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMatch, match)
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultNamespace = "default"
+
+// NewFSLoader returns a Loader which reads snapshots from Flipt declarative
+// state files (features.yml, features.yaml, *.features.yml and
+// *.features.yaml) anywhere within fsys. When names are given only those
+// files are read, regardless of their names.
+//
+// Files are parsed again whenever their size or modification time changes.
+// Files which can't be read or parsed fail the load with an Unavailable
+// error and the previously loaded state is left untouched.
+func NewFSLoader(fsys fs.FS, names ...string) Loader {
+	return &fsLoader{fsys: fsys, names: names}
+}
+
+type fsLoader struct {
+	fsys  fs.FS
+	names []string
+
+	mu          sync.Mutex
+	fingerprint string
+	snapshots   map[string]*Snapshot
+}
+
+func (l *fsLoader) Load(_ context.Context, namespaceKey string) (*Snapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files, fingerprint, err := l.files()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "loading features: %v", err)
+	}
+
+	if l.snapshots == nil || fingerprint != l.fingerprint {
+		snapshots, err := l.parse(files)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "loading features: %v", err)
+		}
+
+		l.snapshots, l.fingerprint = snapshots, fingerprint
+	}
+
+	snap, ok := l.snapshots[namespaceKey]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "namespace %q not found", namespaceKey)
+	}
+
+	return snap, nil
+}
+
+// files returns the sorted names of the state files and a fingerprint of their sizes and modification times.
+func (l *fsLoader) files() ([]string, string, error) {
+	var (
+		files []string
+		b     strings.Builder
+	)
+
+	add := func(name string) error {
+		info, err := fs.Stat(l.fsys, name)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, name)
+		fmt.Fprintf(&b, "%s|%d|%d;", name, info.Size(), info.ModTime().UnixNano())
+
+		return nil
+	}
+
+	if len(l.names) > 0 {
+		for _, name := range l.names {
+			if err := add(name); err != nil {
+				return nil, "", err
+			}
+		}
+
+		return files, b.String(), nil
+	}
+
+	err := fs.WalkDir(l.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isStateFile(d.Name()) {
+			return nil
+		}
+
+		return add(name)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return files, b.String(), nil
+}
+
+func isStateFile(name string) bool {
+	switch name {
+	case "features.yml", "features.yaml":
+		return true
+	}
+
+	return strings.HasSuffix(name, ".features.yml") || strings.HasSuffix(name, ".features.yaml")
+}
+
+// parse builds the snapshots of every namespace defined by the given files.
+func (l *fsLoader) parse(files []string) (map[string]*Snapshot, error) {
+	var docs []*document
+
+	for _, name := range files {
+		parsed, err := l.decode(name)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		docs = append(docs, parsed...)
+	}
+
+	// segments are collected first as rules may refer to segments of the same
+	// namespace which are defined in another file.
+	segments := map[string]map[string]*Segment{}
+
+	for _, doc := range docs {
+		if segments[doc.Namespace] == nil {
+			segments[doc.Namespace] = map[string]*Segment{}
+		}
+
+		for _, s := range doc.Segments {
+			if _, ok := segments[doc.Namespace][s.Key]; ok {
+				return nil, fmt.Errorf("namespace %q: segment %q defined more than once", doc.Namespace, s.Key)
+			}
+
+			segment, err := s.convert()
+			if err != nil {
+				return nil, fmt.Errorf("namespace %q: segment %q: %w", doc.Namespace, s.Key, err)
+			}
+
+			segments[doc.Namespace][s.Key] = segment
+		}
+	}
+
+	snapshots := map[string]*Snapshot{
+		defaultNamespace: newSnapshot(defaultNamespace),
+	}
+
+	for _, doc := range docs {
+		snap, ok := snapshots[doc.Namespace]
+		if !ok {
+			snap = newSnapshot(doc.Namespace)
+			snapshots[doc.Namespace] = snap
+		}
+
+		for _, f := range doc.Flags {
+			if _, ok := snap.Flags[f.Key]; ok {
+				return nil, fmt.Errorf("namespace %q: flag %q defined more than once", doc.Namespace, f.Key)
+			}
+
+			if err := f.convert(snap, segments[doc.Namespace]); err != nil {
+				return nil, fmt.Errorf("namespace %q: flag %q: %w", doc.Namespace, f.Key, err)
+			}
+		}
+	}
+
+	return snapshots, nil
+}
+
+// decode decodes every YAML document within a state file.
+func (l *fsLoader) decode(name string) ([]*document, error) {
+	f, err := l.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		docs []*document
+		dec  = yaml.NewDecoder(f)
+	)
+
+	for {
+		doc := &document{}
+		if err := dec.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+
+			return nil, err
+		}
+
+		if doc.Namespace == "" {
+			doc.Namespace = defaultNamespace
+		}
+
+		docs = append(docs, doc)
+	}
+}
+
+func newSnapshot(namespaceKey string) *Snapshot {
+	return &Snapshot{
+		Namespace: &flipt.Namespace{Key: namespaceKey, Name: namespaceKey},
+		Flags:     map[string]*flipt.Flag{},
+		Rules:     map[string][]*Rule{},
+		Rollouts:  map[string][]*Rollout{},
+	}
+}
+
+// document is a single document of the Flipt declarative state format.
+type document struct {
+	Version   string        `yaml:"version,omitempty"`
+	Namespace string        `yaml:"namespace,omitempty"`
+	Flags     []*docFlag    `yaml:"flags,omitempty"`
+	Segments  []*docSegment `yaml:"segments,omitempty"`
+}
+
+type docFlag struct {
+	Key         string        `yaml:"key"`
+	Name        string        `yaml:"name"`
+	Type        string        `yaml:"type,omitempty"`
+	Description string        `yaml:"description,omitempty"`
+	Enabled     bool          `yaml:"enabled"`
+	Variants    []*docVariant `yaml:"variants,omitempty"`
+	Rules       []*docRule    `yaml:"rules,omitempty"`
+	Rollouts    []*docRollout `yaml:"rollouts,omitempty"`
+}
+
+type docVariant struct {
+	Key         string      `yaml:"key"`
+	Name        string      `yaml:"name,omitempty"`
+	Description string      `yaml:"description,omitempty"`
+	Attachment  interface{} `yaml:"attachment,omitempty"`
+}
+
+type docRule struct {
+	// Segment is either a single segment key or a list of keys with an operator.
+	Segment *docSegmentRef `yaml:"segment,omitempty"`
+	// Segments and Operator are the multiple segment form of version 1.1.
+	Segments      []string           `yaml:"segments,omitempty"`
+	Operator      string             `yaml:"operator,omitempty"`
+	Distributions []*docDistribution `yaml:"distributions,omitempty"`
+}
+
+type docSegmentRef struct {
+	Keys     []string `yaml:"keys,omitempty"`
+	Operator string   `yaml:"operator,omitempty"`
+}
+
+// UnmarshalYAML accepts either a segment key or a mapping of keys and operator.
+func (r *docSegmentRef) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Keys = []string{value.Value}
+
+		return nil
+	}
+
+	type plain docSegmentRef
+
+	return value.Decode((*plain)(r))
+}
+
+type docDistribution struct {
+	VariantKey string  `yaml:"variant"`
+	Rollout    float32 `yaml:"rollout"`
+}
+
+type docRollout struct {
+	Description string             `yaml:"description,omitempty"`
+	Segment     *docRolloutSegment `yaml:"segment,omitempty"`
+	Threshold   *docThreshold      `yaml:"threshold,omitempty"`
+}
+
+type docRolloutSegment struct {
+	Key      string   `yaml:"key,omitempty"`
+	Keys     []string `yaml:"keys,omitempty"`
+	Operator string   `yaml:"operator,omitempty"`
+	Value    bool     `yaml:"value"`
+}
+
+type docThreshold struct {
+	Percentage float32 `yaml:"percentage"`
+	Value      bool    `yaml:"value"`
+}
+
+type docSegment struct {
+	Key         string           `yaml:"key"`
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description,omitempty"`
+	MatchType   string           `yaml:"match_type,omitempty"`
+	Constraints []*docConstraint `yaml:"constraints,omitempty"`
+}
+
+type docConstraint struct {
+	Type        string `yaml:"type"`
+	Property    string `yaml:"property"`
+	Operator    string `yaml:"operator"`
+	Value       string `yaml:"value,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+func (s *docSegment) convert() (*Segment, error) {
+	matchType, err := enumValue(flipt.MatchType_value, s.MatchType, "match type")
+	if err != nil {
+		return nil, err
+	}
+
+	segment := &Segment{Key: s.Key, MatchType: flipt.MatchType(matchType)}
+
+	for _, c := range s.Constraints {
+		typ, err := enumValue(flipt.ComparisonType_value, c.Type, "comparison type")
+		if err != nil {
+			return nil, err
+		}
+
+		segment.Constraints = append(segment.Constraints, &Constraint{
+			Type:     flipt.ComparisonType(typ),
+			Property: c.Property,
+			Operator: strings.ToLower(c.Operator),
+			Value:    c.Value,
+		})
+	}
+
+	return segment, nil
+}
+
+// convert adds the flag along with its rules or rollouts to the snapshot.
+func (f *docFlag) convert(snap *Snapshot, segments map[string]*Segment) error {
+	typ, err := enumValue(flipt.FlagType_value, f.Type, "flag type")
+	if err != nil {
+		return err
+	}
+
+	flag := &flipt.Flag{
+		Key:          f.Key,
+		Name:         f.Name,
+		Description:  f.Description,
+		Enabled:      f.Enabled,
+		NamespaceKey: snap.Namespace.Key,
+		Type:         flipt.FlagType(typ),
+	}
+
+	attachments := make(map[string]string, len(f.Variants))
+
+	for _, v := range f.Variants {
+		var attachment string
+
+		if v.Attachment != nil {
+			b, err := json.Marshal(v.Attachment)
+			if err != nil {
+				return fmt.Errorf("variant %q: attachment: %w", v.Key, err)
+			}
+
+			attachment = string(b)
+		}
+
+		attachments[v.Key] = attachment
+
+		flag.Variants = append(flag.Variants, &flipt.Variant{
+			Key:          v.Key,
+			Name:         v.Name,
+			Description:  v.Description,
+			Attachment:   attachment,
+			NamespaceKey: snap.Namespace.Key,
+		})
+	}
+
+	snap.Flags[f.Key] = flag
+
+	for i, r := range f.Rules {
+		keys, operator := r.Segments, r.Operator
+		if r.Segment != nil {
+			keys, operator = r.Segment.Keys, r.Segment.Operator
+		}
+
+		op, err := enumValue(flipt.SegmentOperator_value, operator, "segment operator")
+		if err != nil {
+			return err
+		}
+
+		rule := &Rule{Rank: int32(i + 1), SegmentOperator: flipt.SegmentOperator(op)}
+
+		if rule.Segments, err = lookupSegments("", keys, segments); err != nil {
+			return err
+		}
+
+		for _, d := range r.Distributions {
+			attachment, ok := attachments[d.VariantKey]
+			if !ok {
+				return fmt.Errorf("variant %q not found", d.VariantKey)
+			}
+
+			rule.Distributions = append(rule.Distributions, &Distribution{
+				Rollout:           d.Rollout,
+				VariantKey:        d.VariantKey,
+				VariantAttachment: attachment,
+			})
+		}
+
+		snap.Rules[f.Key] = append(snap.Rules[f.Key], rule)
+	}
+
+	for i, r := range f.Rollouts {
+		rollout := &Rollout{Rank: int32(i + 1)}
+
+		switch {
+		case r.Threshold != nil:
+			rollout.Threshold = &Threshold{
+				Percentage: r.Threshold.Percentage,
+				Value:      r.Threshold.Value,
+			}
+		case r.Segment != nil:
+			op, err := enumValue(flipt.SegmentOperator_value, r.Segment.Operator, "segment operator")
+			if err != nil {
+				return err
+			}
+
+			matched, err := lookupSegments(r.Segment.Key, r.Segment.Keys, segments)
+			if err != nil {
+				return err
+			}
+
+			rollout.Segment = &RolloutSegment{
+				SegmentOperator: flipt.SegmentOperator(op),
+				Segments:        matched,
+				Value:           r.Segment.Value,
+			}
+		default:
+			continue
+		}
+
+		snap.Rollouts[f.Key] = append(snap.Rollouts[f.Key], rollout)
+	}
+
+	return nil
+}
+
+// enumValue looks up the value of a protobuf enum by name, where an empty
+// name is the zero value.
+func enumValue(values map[string]int32, name, kind string) (int32, error) {
+	if name == "" {
+		return 0, nil
+	}
+
+	v, ok := values[strings.ToUpper(name)]
+	if !ok {
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		return 0, fmt.Errorf("invalid %s %q, expected one of %s", kind, name, strings.Join(keys, ", "))
+	}
+
+	return v, nil
+}
@@ -0,0 +1,208 @@
+package local
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const features = `version: "1.2"
+flags:
+  - key: variant-flag
+    name: Variant Flag
+    type: VARIANT_FLAG_TYPE
+    enabled: true
+    variants:
+      - key: blue
+        attachment:
+          hex: "#0000ff"
+      - key: red
+    rules:
+      - segment:
+          keys: [premium, beta]
+          operator: AND_SEGMENT_OPERATOR
+        distributions:
+          - variant: blue
+            rollout: 100
+      - segment: premium
+        distributions:
+          - variant: red
+            rollout: 100
+  - key: boolean-flag
+    name: Boolean Flag
+    type: BOOLEAN_FLAG_TYPE
+    enabled: false
+    rollouts:
+      - segment:
+          key: premium
+          value: true
+segments:
+  - key: premium
+    name: Premium
+    match_type: ALL_MATCH_TYPE
+    constraints:
+      - type: STRING_COMPARISON_TYPE
+        property: plan
+        operator: eq
+        value: premium
+  - key: beta
+    name: Beta
+    constraints:
+      - type: BOOLEAN_COMPARISON_TYPE
+        property: beta
+        operator: "true"
+---
+namespace: other
+flags:
+  - key: other-flag
+    name: Other Flag
+    enabled: true
+`
+
+func TestFSLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"flags/features.yml": {Data: []byte(features)},
+		"flags/ignored.yml":  {Data: []byte("not: [valid")},
+	}
+
+	client := NewClient(NewFSLoader(fsys), WithRefreshInterval(0))
+	defer client.Close()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		context         map[string]string
+		expectedVariant string
+		expectedAttach  string
+	}{
+		{
+			name:            "all segments",
+			context:         map[string]string{"plan": "premium", "beta": "true"},
+			expectedVariant: "blue",
+			expectedAttach:  `{"hex":"#0000ff"}`,
+		},
+		{
+			name:            "single segment",
+			context:         map[string]string{"plan": "premium"},
+			expectedVariant: "red",
+		},
+		{
+			name:    "no segment",
+			context: map[string]string{"plan": "free"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Variant(ctx, &evaluation.EvaluationRequest{
+				NamespaceKey: "default",
+				FlagKey:      "variant-flag",
+				EntityId:     "entity",
+				Context:      tt.context,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedVariant, resp.VariantKey)
+			assert.Equal(t, tt.expectedAttach, resp.VariantAttachment)
+		})
+	}
+
+	boolean, err := client.Boolean(ctx, &evaluation.EvaluationRequest{
+		NamespaceKey: "default",
+		FlagKey:      "boolean-flag",
+		EntityId:     "entity",
+		Context:      map[string]string{"plan": "premium"},
+	})
+	require.NoError(t, err)
+	assert.True(t, boolean.Enabled)
+
+	flag, err := client.GetFlag(ctx, &flipt.GetFlagRequest{NamespaceKey: "other", Key: "other-flag"})
+	require.NoError(t, err)
+	assert.Equal(t, flipt.FlagType_VARIANT_FLAG_TYPE, flag.Type)
+
+	_, err = client.GetNamespace(ctx, &flipt.GetNamespaceRequest{Key: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestFSLoader_Reload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"features.yml": {Data: []byte("flags:\n  - key: flag\n    enabled: true\n")},
+	}
+
+	var (
+		ctx    = context.Background()
+		loader = NewFSLoader(fsys, "features.yml")
+	)
+
+	snap, err := loader.Load(ctx, "default")
+	require.NoError(t, err)
+	assert.True(t, snap.Flags["flag"].Enabled)
+
+	fsys["features.yml"] = &fstest.MapFile{
+		Data:    []byte("flags:\n  - key: flag\n    enabled: false\n"),
+		ModTime: time.Now(),
+	}
+
+	snap, err = loader.Load(ctx, "default")
+	require.NoError(t, err)
+	assert.False(t, snap.Flags["flag"].Enabled)
+}
+
+func TestFSLoader_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectedErr string
+	}{
+		{
+			name:        "invalid yaml",
+			data:        "flags: [",
+			expectedErr: "loading features: parsing features.yml: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:        "unknown flag type",
+			data:        "flags:\n  - key: flag\n    type: NUMBER_FLAG_TYPE\n",
+			expectedErr: `loading features: namespace "default": flag "flag": invalid flag type "NUMBER_FLAG_TYPE", expected one of BOOLEAN_FLAG_TYPE, VARIANT_FLAG_TYPE`,
+		},
+		{
+			name:        "unknown segment",
+			data:        "flags:\n  - key: flag\n    rules:\n      - segment: missing\n",
+			expectedErr: `loading features: namespace "default": flag "flag": segment "missing" not found`,
+		},
+		{
+			name:        "unknown variant",
+			data:        "flags:\n  - key: flag\n    rules:\n      - distributions:\n          - variant: missing\n            rollout: 100\n",
+			expectedErr: `loading features: namespace "default": flag "flag": variant "missing" not found`,
+		},
+		{
+			name:        "duplicate flag",
+			data:        "flags:\n  - key: flag\n  - key: flag\n",
+			expectedErr: `loading features: namespace "default": flag "flag" defined more than once`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{"features.yml": {Data: []byte(tt.data)}}
+
+			_, err := NewFSLoader(fsys).Load(context.Background(), "default")
+
+			s, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.Unavailable, s.Code())
+			assert.Equal(t, tt.expectedErr, s.Message())
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	flipt "go.flipt.io/flipt/rpc/flipt"
+)
+
+// Snapshot is the complete evaluation state of a single namespace.
+type Snapshot struct {
+	Namespace *flipt.Namespace
+	// Flags are the flags of the namespace by key.
+	Flags map[string]*flipt.Flag
+	// Rules are the variant flag rules by flag key, ordered by rank.
+	Rules map[string][]*Rule
+	// Rollouts are the boolean flag rollouts by flag key, ordered by rank.
+	Rollouts map[string][]*Rollout
+}
+
+// Segment is a segment with its constraints.
+type Segment struct {
+	Key         string
+	MatchType   flipt.MatchType
+	Constraints []*Constraint
+}
+
+// Constraint is a single segment constraint.
+type Constraint struct {
+	Type     flipt.ComparisonType
+	Property string
+	Operator string
+	Value    string
+}
+
+// Rule matches segments of a variant flag to a distribution of variants.
+type Rule struct {
+	Rank            int32
+	SegmentOperator flipt.SegmentOperator
+	Segments        []*Segment
+	Distributions   []*Distribution
+}
+
+// Distribution is the share of entities matched by a rule which receive a variant.
+type Distribution struct {
+	Rollout           float32
+	VariantKey        string
+	VariantAttachment string
+}
+
+// Rollout is a single rollout of a boolean flag. Exactly one of
+// Threshold and Segment is set.
+type Rollout struct {
+	Rank      int32
+	Threshold *Threshold
+	Segment   *RolloutSegment
+}
+
+// Threshold returns Value for the given percentage of entities.
+type Threshold struct {
+	Percentage float32
+	Value      bool
+}
+
+// RolloutSegment returns Value for entities matched by the segments.
+type RolloutSegment struct {
+	SegmentOperator flipt.SegmentOperator
+	Segments        []*Segment
+	Value           bool
+}
+
+// Lister is the subset of the Flipt API used to download the state of a namespace.
+// It is implemented by the Flipt SDK client for both gRPC and HTTP.
+type Lister interface {
+	GetNamespace(ctx context.Context, n *flipt.GetNamespaceRequest) (*flipt.Namespace, error)
+	ListFlags(ctx context.Context, l *flipt.ListFlagRequest) (*flipt.FlagList, error)
+	ListSegments(ctx context.Context, l *flipt.ListSegmentRequest) (*flipt.SegmentList, error)
+	ListRules(ctx context.Context, l *flipt.ListRuleRequest) (*flipt.RuleList, error)
+	ListRollouts(ctx context.Context, l *flipt.ListRolloutRequest) (*flipt.RolloutList, error)
+}
+
+// Loader loads the snapshot of a namespace.
+type Loader interface {
+	Load(ctx context.Context, namespaceKey string) (*Snapshot, error)
+}
+
+// LoaderFunc is an adapter to allow the use of ordinary functions as a Loader.
+type LoaderFunc func(ctx context.Context, namespaceKey string) (*Snapshot, error)
+
+// Load calls f(ctx, namespaceKey).
+func (f LoaderFunc) Load(ctx context.Context, namespaceKey string) (*Snapshot, error) {
+	return f(ctx, namespaceKey)
+}
+
+// NewAPILoader returns a Loader which downloads snapshots using the Flipt API.
+func NewAPILoader(lister Lister) Loader {
+	return &apiLoader{lister: lister}
+}
+
+type apiLoader struct {
+	lister Lister
+}
+
+func (l *apiLoader) Load(ctx context.Context, namespaceKey string) (*Snapshot, error) {
+	ns, err := l.lister.GetNamespace(ctx, &flipt.GetNamespaceRequest{Key: namespaceKey})
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := l.listFlags(ctx, namespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := l.listSegments(ctx, namespaceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Namespace: ns,
+		Flags:     make(map[string]*flipt.Flag, len(flags)),
+		Rules:     map[string][]*Rule{},
+		Rollouts:  map[string][]*Rollout{},
+	}
+
+	for _, flag := range flags {
+		snap.Flags[flag.Key] = flag
+
+		switch flag.Type {
+		case flipt.FlagType_BOOLEAN_FLAG_TYPE:
+			rollouts, err := l.listRollouts(ctx, namespaceKey, flag.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			if snap.Rollouts[flag.Key], err = convertRollouts(rollouts, segments); err != nil {
+				return nil, fmt.Errorf("flag %q: %w", flag.Key, err)
+			}
+		default:
+			rules, err := l.listRules(ctx, namespaceKey, flag.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			if snap.Rules[flag.Key], err = convertRules(flag, rules, segments); err != nil {
+				return nil, fmt.Errorf("flag %q: %w", flag.Key, err)
+			}
+		}
+	}
+
+	return snap, nil
+}
+
+func (l *apiLoader) listFlags(ctx context.Context, namespaceKey string) ([]*flipt.Flag, error) {
+	var (
+		flags     []*flipt.Flag
+		pageToken string
+	)
+
+	for {
+		list, err := l.lister.ListFlags(ctx, &flipt.ListFlagRequest{NamespaceKey: namespaceKey, PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+
+		flags = append(flags, list.Flags...)
+
+		if list.NextPageToken == "" {
+			return flags, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+func (l *apiLoader) listSegments(ctx context.Context, namespaceKey string) (map[string]*Segment, error) {
+	var (
+		segments  = map[string]*Segment{}
+		pageToken string
+	)
+
+	for {
+		list, err := l.lister.ListSegments(ctx, &flipt.ListSegmentRequest{NamespaceKey: namespaceKey, PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range list.Segments {
+			segment := &Segment{Key: s.Key, MatchType: s.MatchType}
+			for _, c := range s.Constraints {
+				segment.Constraints = append(segment.Constraints, &Constraint{
+					Type:     c.Type,
+					Property: c.Property,
+					Operator: c.Operator,
+					Value:    c.Value,
+				})
+			}
+
+			segments[s.Key] = segment
+		}
+
+		if list.NextPageToken == "" {
+			return segments, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+func (l *apiLoader) listRules(ctx context.Context, namespaceKey, flagKey string) ([]*flipt.Rule, error) {
+	var (
+		rules     []*flipt.Rule
+		pageToken string
+	)
+
+	for {
+		list, err := l.lister.ListRules(ctx, &flipt.ListRuleRequest{NamespaceKey: namespaceKey, FlagKey: flagKey, PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, list.Rules...)
+
+		if list.NextPageToken == "" {
+			return rules, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+func (l *apiLoader) listRollouts(ctx context.Context, namespaceKey, flagKey string) ([]*flipt.Rollout, error) {
+	var (
+		rollouts  []*flipt.Rollout
+		pageToken string
+	)
+
+	for {
+		list, err := l.lister.ListRollouts(ctx, &flipt.ListRolloutRequest{NamespaceKey: namespaceKey, FlagKey: flagKey, PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+
+		rollouts = append(rollouts, list.Rules...)
+
+		if list.NextPageToken == "" {
+			return rollouts, nil
+		}
+
+		pageToken = list.NextPageToken
+	}
+}
+
+func convertRules(flag *flipt.Flag, rules []*flipt.Rule, segments map[string]*Segment) ([]*Rule, error) {
+	variants := make(map[string]*flipt.Variant, len(flag.Variants))
+	for _, v := range flag.Variants {
+		variants[v.Id] = v
+	}
+
+	out := make([]*Rule, 0, len(rules))
+
+	for _, r := range rules {
+		rule := &Rule{Rank: r.Rank, SegmentOperator: r.SegmentOperator}
+
+		var err error
+		if rule.Segments, err = lookupSegments(r.SegmentKey, r.SegmentKeys, segments); err != nil {
+			return nil, err
+		}
+
+		for _, d := range r.Distributions {
+			v, ok := variants[d.VariantId]
+			if !ok {
+				return nil, fmt.Errorf("variant %q not found", d.VariantId)
+			}
+
+			rule.Distributions = append(rule.Distributions, &Distribution{
+				Rollout:           d.Rollout,
+				VariantKey:        v.Key,
+				VariantAttachment: v.Attachment,
+			})
+		}
+
+		out = append(out, rule)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Rank < out[j].Rank })
+
+	return out, nil
+}
+
+func convertRollouts(rollouts []*flipt.Rollout, segments map[string]*Segment) ([]*Rollout, error) {
+	out := make([]*Rollout, 0, len(rollouts))
+
+	for _, r := range rollouts {
+		rollout := &Rollout{Rank: r.Rank}
+
+		switch {
+		case r.GetThreshold() != nil:
+			rollout.Threshold = &Threshold{
+				Percentage: r.GetThreshold().Percentage,
+				Value:      r.GetThreshold().Value,
+			}
+		case r.GetSegment() != nil:
+			rs := r.GetSegment()
+
+			matched, err := lookupSegments(rs.SegmentKey, rs.SegmentKeys, segments)
+			if err != nil {
+				return nil, err
+			}
+
+			rollout.Segment = &RolloutSegment{
+				SegmentOperator: rs.SegmentOperator,
+				Segments:        matched,
+				Value:           rs.Value,
+			}
+		default:
+			continue
+		}
+
+		out = append(out, rollout)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Rank < out[j].Rank })
+
+	return out, nil
+}
+
+// lookupSegments resolves the segments referenced by a rule or rollout, which
+// refer to either a single segment key or a list of segment keys.
+func lookupSegments(key string, keys []string, segments map[string]*Segment) ([]*Segment, error) {
+	if len(keys) == 0 && key != "" {
+		keys = []string{key}
+	}
+
+	out := make([]*Segment, 0, len(keys))
+
+	for _, k := range keys {
+		s, ok := segments[k]
+		if !ok {
+			return nil, fmt.Errorf("segment %q not found", k)
+		}
+
+		out = append(out, s)
+	}
+
+	return out, nil
+}
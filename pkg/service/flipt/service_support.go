@@ -24,6 +24,61 @@ func (_m *MockClient) EXPECT() *MockClient_Expecter {
 	return &MockClient_Expecter{mock: &_m.Mock}
 }
 
+// Batch provides a mock function with given fields: ctx, v
+func (_m *MockClient) Batch(ctx context.Context, v *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error) {
+	ret := _m.Called(ctx, v)
+
+	var r0 *evaluation.BatchEvaluationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error)); ok {
+		return rf(ctx, v)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *evaluation.BatchEvaluationRequest) *evaluation.BatchEvaluationResponse); ok {
+		r0 = rf(ctx, v)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*evaluation.BatchEvaluationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *evaluation.BatchEvaluationRequest) error); ok {
+		r1 = rf(ctx, v)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_Batch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Batch'
+type MockClient_Batch_Call struct {
+	*mock.Call
+}
+
+// Batch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - v *evaluation.BatchEvaluationRequest
+func (_e *MockClient_Expecter) Batch(ctx interface{}, v interface{}) *MockClient_Batch_Call {
+	return &MockClient_Batch_Call{Call: _e.mock.On("Batch", ctx, v)}
+}
+
+func (_c *MockClient_Batch_Call) Run(run func(ctx context.Context, v *evaluation.BatchEvaluationRequest)) *MockClient_Batch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*evaluation.BatchEvaluationRequest))
+	})
+	return _c
+}
+
+func (_c *MockClient_Batch_Call) Return(_a0 *evaluation.BatchEvaluationResponse, _a1 error) *MockClient_Batch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_Batch_Call) RunAndReturn(run func(context.Context, *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error)) *MockClient_Batch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Boolean provides a mock function with given fields: ctx, v
 func (_m *MockClient) Boolean(ctx context.Context, v *evaluation.EvaluationRequest) (*evaluation.BooleanEvaluationResponse, error) {
 	ret := _m.Called(ctx, v)
@@ -134,6 +189,116 @@ func (_c *MockClient_GetFlag_Call) RunAndReturn(run func(context.Context, *rpcfl
 	return _c
 }
 
+// GetNamespace provides a mock function with given fields: ctx, n
+func (_m *MockClient) GetNamespace(ctx context.Context, n *rpcflipt.GetNamespaceRequest) (*rpcflipt.Namespace, error) {
+	ret := _m.Called(ctx, n)
+
+	var r0 *rpcflipt.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *rpcflipt.GetNamespaceRequest) (*rpcflipt.Namespace, error)); ok {
+		return rf(ctx, n)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *rpcflipt.GetNamespaceRequest) *rpcflipt.Namespace); ok {
+		r0 = rf(ctx, n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rpcflipt.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *rpcflipt.GetNamespaceRequest) error); ok {
+		r1 = rf(ctx, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_GetNamespace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNamespace'
+type MockClient_GetNamespace_Call struct {
+	*mock.Call
+}
+
+// GetNamespace is a helper method to define mock.On call
+//   - ctx context.Context
+//   - n *rpcflipt.GetNamespaceRequest
+func (_e *MockClient_Expecter) GetNamespace(ctx interface{}, n interface{}) *MockClient_GetNamespace_Call {
+	return &MockClient_GetNamespace_Call{Call: _e.mock.On("GetNamespace", ctx, n)}
+}
+
+func (_c *MockClient_GetNamespace_Call) Run(run func(ctx context.Context, n *rpcflipt.GetNamespaceRequest)) *MockClient_GetNamespace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*rpcflipt.GetNamespaceRequest))
+	})
+	return _c
+}
+
+func (_c *MockClient_GetNamespace_Call) Return(_a0 *rpcflipt.Namespace, _a1 error) *MockClient_GetNamespace_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_GetNamespace_Call) RunAndReturn(run func(context.Context, *rpcflipt.GetNamespaceRequest) (*rpcflipt.Namespace, error)) *MockClient_GetNamespace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFlags provides a mock function with given fields: ctx, l
+func (_m *MockClient) ListFlags(ctx context.Context, l *rpcflipt.ListFlagRequest) (*rpcflipt.FlagList, error) {
+	ret := _m.Called(ctx, l)
+
+	var r0 *rpcflipt.FlagList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *rpcflipt.ListFlagRequest) (*rpcflipt.FlagList, error)); ok {
+		return rf(ctx, l)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *rpcflipt.ListFlagRequest) *rpcflipt.FlagList); ok {
+		r0 = rf(ctx, l)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rpcflipt.FlagList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *rpcflipt.ListFlagRequest) error); ok {
+		r1 = rf(ctx, l)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClient_ListFlags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFlags'
+type MockClient_ListFlags_Call struct {
+	*mock.Call
+}
+
+// ListFlags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - l *rpcflipt.ListFlagRequest
+func (_e *MockClient_Expecter) ListFlags(ctx interface{}, l interface{}) *MockClient_ListFlags_Call {
+	return &MockClient_ListFlags_Call{Call: _e.mock.On("ListFlags", ctx, l)}
+}
+
+func (_c *MockClient_ListFlags_Call) Run(run func(ctx context.Context, l *rpcflipt.ListFlagRequest)) *MockClient_ListFlags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*rpcflipt.ListFlagRequest))
+	})
+	return _c
+}
+
+func (_c *MockClient_ListFlags_Call) Return(_a0 *rpcflipt.FlagList, _a1 error) *MockClient_ListFlags_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClient_ListFlags_Call) RunAndReturn(run func(context.Context, *rpcflipt.ListFlagRequest) (*rpcflipt.FlagList, error)) *MockClient_ListFlags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Variant provides a mock function with given fields: ctx, v
 func (_m *MockClient) Variant(ctx context.Context, v *evaluation.EvaluationRequest) (*evaluation.VariantEvaluationResponse, error) {
 	ret := _m.Called(ctx, v)
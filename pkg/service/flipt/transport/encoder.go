@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ContextEncoder converts the values of an evaluation context to the strings
+// expected by Flipt, preserving their type:
+//   - times are formatted as RFC3339, as expected by datetime constraints
+//   - numbers are formatted in their shortest decimal form, without exponent
+//   - maps, slices and structs are encoded as JSON
+//   - nil values are omitted
+type ContextEncoder struct {
+	flatten  bool
+	encoders []typeEncoder
+}
+
+type typeEncoder struct {
+	typ    reflect.Type
+	encode func(v interface{}) (string, error)
+}
+
+// EncoderOption is a ContextEncoder option.
+type EncoderOption func(*ContextEncoder)
+
+// WithFlattening encodes nested maps as one attribute per leaf value, keyed by
+// the dot-separated path to the value, instead of as JSON.
+// For example {"user": {"plan": "pro"}} is encoded as {"user.plan": "pro"}.
+func WithFlattening() EncoderOption {
+	return func(e *ContextEncoder) {
+		e.flatten = true
+	}
+}
+
+// WithEncoder registers an encoder for the values of type T, which takes
+// precedence over the default encoding. When T is an interface, it applies
+// to values of every type implementing it. Encoders registered first take
+// precedence. Encoders don't apply to values within JSON encoded values.
+func WithEncoder[T any](encode func(v T) (string, error)) EncoderOption {
+	return func(e *ContextEncoder) {
+		e.encoders = append(e.encoders, typeEncoder{
+			typ: reflect.TypeOf((*T)(nil)).Elem(),
+			encode: func(v interface{}) (string, error) {
+				return encode(v.(T))
+			},
+		})
+	}
+}
+
+// NewContextEncoder creates a new ContextEncoder.
+func NewContextEncoder(opts ...EncoderOption) *ContextEncoder {
+	e := &ContextEncoder{}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Encode converts an evaluation context to the context of a Flipt evaluation request.
+func (e *ContextEncoder) Encode(evalCtx map[string]interface{}) (map[string]string, error) {
+	out := make(map[string]string, len(evalCtx))
+
+	for k, v := range evalCtx {
+		if err := e.encode(out, k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+func (e *ContextEncoder) encode(out map[string]string, key string, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+
+		if _, ok := e.encoder(rv.Type()); !ok {
+			return e.encode(out, key, rv.Elem().Interface())
+		}
+	}
+
+	if encode, ok := e.encoder(rv.Type()); ok {
+		s, err := encode(v)
+		if err != nil {
+			return fmt.Errorf("encoding %q: %w", key, err)
+		}
+
+		out[key] = s
+
+		return nil
+	}
+
+	if e.flatten && rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := e.encode(out, key+"."+iter.Key().String(), iter.Value().Interface()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	s, err := encodeValue(rv)
+	if err != nil {
+		return fmt.Errorf("encoding %q: %w", key, err)
+	}
+
+	out[key] = s
+
+	return nil
+}
+
+// encoder returns the registered encoder for values of the given type.
+func (e *ContextEncoder) encoder(typ reflect.Type) (func(v interface{}) (string, error), bool) {
+	for _, enc := range e.encoders {
+		if typ == enc.typ || (enc.typ.Kind() == reflect.Interface && typ.Implements(enc.typ)) {
+			return enc.encode, true
+		}
+	}
+
+	return nil, false
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// encodeValue applies the default encoding of a value.
+func encodeValue(rv reflect.Value) (string, error) {
+	if rv.Type() == timeType {
+		return rv.Interface().(time.Time).Format(time.RFC3339), nil
+	}
+
+	if n, ok := rv.Interface().(json.Number); ok {
+		return n.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		b, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	default:
+		return fmt.Sprint(rv.Interface()), nil
+	}
+}
@@ -0,0 +1,138 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type plan struct {
+	name string
+}
+
+func TestContextEncoder(t *testing.T) {
+	var (
+		created = time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+		count   = 3
+	)
+
+	tests := []struct {
+		name     string
+		opts     []EncoderOption
+		evalCtx  map[string]interface{}
+		expected map[string]string
+	}{
+		{
+			name: "scalars",
+			evalCtx: map[string]interface{}{
+				"string":  "foo",
+				"bool":    true,
+				"int":     42,
+				"uint8":   uint8(7),
+				"pointer": &count,
+				"nil":     nil,
+			},
+			expected: map[string]string{
+				"string":  "foo",
+				"bool":    "true",
+				"int":     "42",
+				"uint8":   "7",
+				"pointer": "3",
+			},
+		},
+		{
+			name: "numbers",
+			evalCtx: map[string]interface{}{
+				"large":   1e21,
+				"small":   0.000001,
+				"float32": float32(0.1),
+				"number":  json.Number("12.50"),
+			},
+			expected: map[string]string{
+				"large":   "1000000000000000000000",
+				"small":   "0.000001",
+				"float32": "0.1",
+				"number":  "12.50",
+			},
+		},
+		{
+			name: "time",
+			evalCtx: map[string]interface{}{
+				"created": created,
+				"local":   created.In(time.FixedZone("CET", 3600)),
+				"pointer": &created,
+			},
+			expected: map[string]string{
+				"created": "2024-03-01T12:30:00Z",
+				"local":   "2024-03-01T13:30:00+01:00",
+				"pointer": "2024-03-01T12:30:00Z",
+			},
+		},
+		{
+			name: "json",
+			evalCtx: map[string]interface{}{
+				"slice": []string{"a", "b"},
+				"map":   map[string]interface{}{"plan": "pro", "seats": 5},
+			},
+			expected: map[string]string{
+				"slice": `["a","b"]`,
+				"map":   `{"plan":"pro","seats":5}`,
+			},
+		},
+		{
+			name: "flattened",
+			opts: []EncoderOption{WithFlattening()},
+			evalCtx: map[string]interface{}{
+				"user": map[string]interface{}{
+					"plan":    "pro",
+					"address": map[string]string{"country": "FR"},
+					"roles":   []string{"admin"},
+				},
+			},
+			expected: map[string]string{
+				"user.plan":            "pro",
+				"user.address.country": "FR",
+				"user.roles":           `["admin"]`,
+			},
+		},
+		{
+			name: "custom encoders",
+			opts: []EncoderOption{
+				WithEncoder(func(p plan) (string, error) { return "plan:" + p.name, nil }),
+				WithEncoder(func(s fmt.Stringer) (string, error) { return s.String(), nil }),
+			},
+			evalCtx: map[string]interface{}{
+				"plan":    plan{name: "pro"},
+				"timeout": 1500 * time.Millisecond,
+			},
+			expected: map[string]string{
+				"plan":    "plan:pro",
+				"timeout": "1.5s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := NewContextEncoder(tt.opts...).Encode(tt.evalCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestContextEncoder_Errors(t *testing.T) {
+	_, err := NewContextEncoder().Encode(map[string]interface{}{"nan": []float64{math.NaN()}})
+	assert.ErrorContains(t, err, `encoding "nan"`)
+
+	_, err = NewContextEncoder(WithEncoder(func(plan) (string, error) {
+		return "", errors.New("unsupported plan")
+	})).Encode(map[string]interface{}{"plan": plan{}})
+	assert.EqualError(t, err, `encoding "plan": unsupported plan`)
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"fmt"
+	"strings"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+)
+
+// EntityIDResolver derives the entity ID of an evaluation from its encoded
+// context when the targeting key is missing.
+type EntityIDResolver func(ec map[string]string) (string, error)
+
+// WithEntityIDResolver sets the resolver of the entity ID of evaluations whose
+// context lacks a targeting key. By default StrictEntityID is used, failing
+// such evaluations with a TARGETING_KEY_MISSING error.
+func WithEntityIDResolver(resolver EntityIDResolver) Option {
+	return func(s *Service) {
+		s.entityIDResolver = resolver
+	}
+}
+
+// StrictEntityID fails every evaluation without a targeting key.
+func StrictEntityID() EntityIDResolver {
+	return func(map[string]string) (string, error) {
+		return "", of.NewTargetingKeyMissingResolutionError("targetingKey is missing")
+	}
+}
+
+// AttributeEntityID uses the value of the first of the given attributes which is set.
+func AttributeEntityID(keys ...string) EntityIDResolver {
+	return func(ec map[string]string) (string, error) {
+		for _, key := range keys {
+			if v := ec[key]; v != "" {
+				return v, nil
+			}
+		}
+
+		return "", of.NewTargetingKeyMissingResolutionError(fmt.Sprintf("targetingKey is missing and none of %q is set", keys))
+	}
+}
+
+// CompositeEntityID joins the values of the given attributes with the separator,
+// such as an organization and a user ID. Every attribute must be set.
+func CompositeEntityID(separator string, keys ...string) EntityIDResolver {
+	return func(ec map[string]string) (string, error) {
+		values := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			v := ec[key]
+			if v == "" {
+				return "", of.NewTargetingKeyMissingResolutionError(fmt.Sprintf("targetingKey is missing and %q is not set", key))
+			}
+
+			values = append(values, v)
+		}
+
+		return strings.Join(values, separator), nil
+	}
+}
+
+// RandomEntityID generates a random entity ID for every evaluation, for anonymous
+// traffic. Such evaluations aren't sticky: percentage rollouts and distributions
+// may resolve differently for the same caller each time.
+func RandomEntityID() EntityIDResolver {
+	return func(map[string]string) (string, error) {
+		return randomUUID(), nil
+	}
+}
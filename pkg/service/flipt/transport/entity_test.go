@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityIDResolver(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolver    EntityIDResolver
+		evalCtx     map[string]interface{}
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "targeting key",
+			resolver: AttributeEntityID("userId"),
+			evalCtx:  map[string]interface{}{of.TargetingKey: entityID, "userId": "user"},
+			expected: entityID,
+		},
+		{
+			name:        "strict by default",
+			evalCtx:     map[string]interface{}{"userId": "user"},
+			expectedErr: of.NewTargetingKeyMissingResolutionError("targetingKey is missing"),
+		},
+		{
+			name:        "strict",
+			resolver:    StrictEntityID(),
+			evalCtx:     map[string]interface{}{"userId": "user"},
+			expectedErr: of.NewTargetingKeyMissingResolutionError("targetingKey is missing"),
+		},
+		{
+			name:     "attribute",
+			resolver: AttributeEntityID("userId", "sessionId"),
+			evalCtx:  map[string]interface{}{"sessionId": "session"},
+			expected: "session",
+		},
+		{
+			name:        "attribute missing",
+			resolver:    AttributeEntityID("userId", "sessionId"),
+			evalCtx:     map[string]interface{}{},
+			expectedErr: of.NewTargetingKeyMissingResolutionError(`targetingKey is missing and none of ["userId" "sessionId"] is set`),
+		},
+		{
+			name:     "composite",
+			resolver: CompositeEntityID(":", "orgId", "userId"),
+			evalCtx:  map[string]interface{}{"orgId": 42, "userId": "user"},
+			expected: "42:user",
+		},
+		{
+			name:        "composite missing",
+			resolver:    CompositeEntityID(":", "orgId", "userId"),
+			evalCtx:     map[string]interface{}{"orgId": 42},
+			expectedErr: of.NewTargetingKeyMissingResolutionError(`targetingKey is missing and "userId" is not set`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{entityIDResolver: tt.resolver}
+
+			req, err := s.request(context.Background(), "foo-namespace", "foo", tt.evalCtx)
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.EntityId)
+		})
+	}
+}
+
+func TestRandomEntityID(t *testing.T) {
+	s := &Service{entityIDResolver: RandomEntityID()}
+
+	first, err := s.request(context.Background(), "foo-namespace", "foo", map[string]interface{}{})
+	require.NoError(t, err)
+
+	second, err := s.request(context.Background(), "foo-namespace", "foo", map[string]interface{}{})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, first.EntityId)
+	assert.NotEqual(t, first.EntityId, second.EntityId)
+}
@@ -0,0 +1,438 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultDialTimeout         = 5 * time.Second
+)
+
+// FailoverListener is called whenever calls to Flipt switch from one endpoint to another.
+type FailoverListener func(from, to string)
+
+// WithAddresses sets an ordered list of addresses of Flipt replicas, each of
+// which may use either gRPC or HTTP. Calls are routed to the first healthy
+// address and fail over to the next ones when it becomes unavailable.
+// The health of each address is checked in the background once the service
+// is started, so that calls return to a preferred address once it has recovered.
+func WithAddresses(addresses ...string) Option {
+	return func(s *Service) {
+		if len(addresses) == 0 {
+			return
+		}
+
+		s.address = addresses[0]
+		s.addresses = append([]string{}, addresses...)
+	}
+}
+
+// WithHealthCheckInterval sets the interval at which the health of each of the
+// addresses set with WithAddresses is checked. It defaults to 10 seconds.
+func WithHealthCheckInterval(interval time.Duration) Option {
+	return func(s *Service) {
+		s.healthCheckInterval = interval
+	}
+}
+
+// WithDialTimeout bounds the time spent connecting to each of the addresses set
+// with WithAddresses when routing a call, so that an unreachable address is
+// failed over even when the call has no deadline. It defaults to 5 seconds.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.dialTimeout = timeout
+	}
+}
+
+// WithFailoverListener registers listeners to be notified when calls switch
+// between the addresses set with WithAddresses.
+func WithFailoverListener(listeners ...FailoverListener) Option {
+	return func(s *Service) {
+		s.failoverListeners = append(s.failoverListeners, listeners...)
+	}
+}
+
+// WithLogger sets the logger reporting switches between addresses.
+// The default slog logger is used by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Service) {
+		s.logger = logger
+	}
+}
+
+// newFailover returns a failover client over a service per address, each of
+// which is configured with the options of s and connects to its address only.
+func (s *Service) newFailover() *failover {
+	endpoints := make([]*Service, 0, len(s.addresses))
+	for _, address := range s.addresses {
+		endpoints = append(endpoints, New(append(append([]Option{}, s.opts...), func(e *Service) {
+			e.address = address
+			// calls are failed over, and flags evaluated locally, by s.
+			e.addresses = nil
+			e.local = false
+			e.client = nil
+			e.stateListeners = nil
+		})...))
+	}
+
+	f := newFailover(endpoints, s.logger, s.failoverListeners)
+	f.dialTimeout = s.dialTimeout
+
+	// the connection states of the endpoint serving calls are those of s,
+	// while those of the endpoints on standby are only used for routing.
+	for _, e := range f.endpoints {
+		e.service.stateListeners = []StateListener{f.whileActive(e, s.setState)}
+	}
+
+	return f
+}
+
+// Start starts checking the health of the addresses set with WithAddresses in
+// the background, until the service is closed. It does nothing otherwise.
+func (s *Service) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.addresses) < 2 {
+		return
+	}
+
+	if s.failover == nil {
+		s.failover = s.newFailover()
+		s.client = s.wrap(s.failover)
+	}
+
+	s.failover.start(s.healthCheckInterval)
+}
+
+// failover is a Flipt client routing calls to the first healthy of an ordered list of endpoints.
+type failover struct {
+	endpoints   []*endpoint
+	logger      *slog.Logger
+	listeners   []FailoverListener
+	dialTimeout time.Duration
+
+	mu     sync.Mutex
+	active int
+	stop   context.CancelFunc
+	done   chan struct{}
+}
+
+type endpoint struct {
+	service *Service
+	healthy bool
+}
+
+func newFailover(services []*Service, logger *slog.Logger, listeners []FailoverListener) *failover {
+	f := &failover{
+		logger:    logger,
+		listeners: listeners,
+	}
+
+	// endpoints are assumed healthy until proven otherwise.
+	for _, service := range services {
+		f.endpoints = append(f.endpoints, &endpoint{service: service, healthy: true})
+	}
+
+	return f
+}
+
+// start checks the health of every endpoint at the given interval until the failover is closed.
+func (f *failover) start(interval time.Duration) {
+	if interval <= 0 || f.stop != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.stop = cancel
+	f.done = make(chan struct{})
+
+	go func() {
+		defer close(f.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.checkAll(ctx, interval)
+			}
+		}
+	}()
+}
+
+// checkAll concurrently checks the health of every endpoint, each within the given timeout.
+func (f *failover) checkAll(ctx context.Context, timeout time.Duration) {
+	var wg sync.WaitGroup
+
+	for _, e := range f.endpoints {
+		wg.Add(1)
+
+		go func(e *endpoint) {
+			defer wg.Done()
+
+			cctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := f.check(cctx, e)
+			if ctx.Err() != nil {
+				return
+			}
+
+			f.setHealthy(e, !unavailable(err))
+		}(e)
+	}
+
+	wg.Wait()
+}
+
+// check calls an endpoint with a lightweight request. Any response, even an error one, shows it's reachable.
+func (f *failover) check(ctx context.Context, e *endpoint) error {
+	client, err := e.service.instance(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.GetNamespace(ctx, &flipt.GetNamespaceRequest{Key: "default"})
+
+	return err
+}
+
+// setHealthy records the health of an endpoint and reports the switchover when
+// it changes which endpoint is preferred.
+func (f *failover) setHealthy(e *endpoint, healthy bool) {
+	f.mu.Lock()
+
+	e.healthy = healthy
+
+	from := f.active
+	for i, e := range f.endpoints {
+		if e.healthy {
+			f.active = i
+
+			break
+		}
+	}
+
+	to := f.active
+	f.mu.Unlock()
+
+	if from == to {
+		return
+	}
+
+	fromAddr, toAddr := f.endpoints[from].service.address, f.endpoints[to].service.address
+
+	if f.logger != nil {
+		f.logger.Warn("switching Flipt endpoint", "from", fromAddr, "to", toAddr)
+	}
+
+	for _, listener := range f.listeners {
+		listener(fromAddr, toAddr)
+	}
+}
+
+// whileActive returns a state listener calling listener with the states of
+// an endpoint while it's the one calls are routed to.
+func (f *failover) whileActive(e *endpoint, listener StateListener) StateListener {
+	return func(state connectivity.State) {
+		f.mu.Lock()
+		active := f.endpoints[f.active] == e
+		f.mu.Unlock()
+
+		if active {
+			listener(state)
+		}
+	}
+}
+
+// candidates returns the endpoints to call in order, the healthy ones or all of them when none is.
+func (f *failover) candidates() []*endpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var healthy []*endpoint
+	for _, e := range f.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		}
+	}
+
+	if len(healthy) == 0 {
+		return f.endpoints
+	}
+
+	return healthy
+}
+
+// close stops the health checks and closes the connections to every endpoint.
+func (f *failover) close() error {
+	if f.stop != nil {
+		f.stop()
+		<-f.done
+	}
+
+	var errs []error
+	for _, e := range f.endpoints {
+		errs = append(errs, e.service.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// unavailable returns true when an error shows that an endpoint could not be reached.
+func unavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return true
+	}
+
+	return st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded
+}
+
+// route calls the candidate endpoints in order until one of them can be reached.
+// Endpoints which can't be reached are marked unhealthy, unless the caller gave up on the call.
+func route[T any](ctx context.Context, f *failover, call func(client offlipt.Client) (T, error)) (T, error) {
+	var (
+		v   T
+		err error
+	)
+
+	for _, e := range f.candidates() {
+		var client offlipt.Client
+
+		client, err = f.instance(ctx, e)
+		if err == nil {
+			v, err = call(client)
+		}
+
+		if ctx.Err() != nil {
+			return v, err
+		}
+
+		if !unavailable(err) {
+			f.setHealthy(e, true)
+
+			return v, err
+		}
+
+		f.setHealthy(e, false)
+	}
+
+	return v, err
+}
+
+// instance returns the client of an endpoint, connecting within the dial timeout.
+func (f *failover) instance(ctx context.Context, e *endpoint) (offlipt.Client, error) {
+	if f.dialTimeout <= 0 {
+		return e.service.instance(ctx)
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, f.dialTimeout)
+	defer cancel()
+
+	return e.service.instance(dctx)
+}
+
+// lister returns the client of an endpoint as a local.Lister, which the Flipt SDK client implements.
+func lister(client offlipt.Client) (local.Lister, error) {
+	l, ok := client.(local.Lister)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "listing is not supported by the client")
+	}
+
+	return l, nil
+}
+
+func (f *failover) GetNamespace(ctx context.Context, r *flipt.GetNamespaceRequest) (*flipt.Namespace, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.Namespace, error) {
+		return client.GetNamespace(ctx, r)
+	})
+}
+
+func (f *failover) GetFlag(ctx context.Context, r *flipt.GetFlagRequest) (*flipt.Flag, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.Flag, error) {
+		return client.GetFlag(ctx, r)
+	})
+}
+
+func (f *failover) ListFlags(ctx context.Context, r *flipt.ListFlagRequest) (*flipt.FlagList, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.FlagList, error) {
+		return client.ListFlags(ctx, r)
+	})
+}
+
+func (f *failover) ListSegments(ctx context.Context, r *flipt.ListSegmentRequest) (*flipt.SegmentList, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.SegmentList, error) {
+		l, err := lister(client)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.ListSegments(ctx, r)
+	})
+}
+
+func (f *failover) ListRules(ctx context.Context, r *flipt.ListRuleRequest) (*flipt.RuleList, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.RuleList, error) {
+		l, err := lister(client)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.ListRules(ctx, r)
+	})
+}
+
+func (f *failover) ListRollouts(ctx context.Context, r *flipt.ListRolloutRequest) (*flipt.RolloutList, error) {
+	return route(ctx, f, func(client offlipt.Client) (*flipt.RolloutList, error) {
+		l, err := lister(client)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.ListRollouts(ctx, r)
+	})
+}
+
+func (f *failover) Variant(ctx context.Context, r *evaluation.EvaluationRequest) (*evaluation.VariantEvaluationResponse, error) {
+	return route(ctx, f, func(client offlipt.Client) (*evaluation.VariantEvaluationResponse, error) {
+		return client.Variant(ctx, r)
+	})
+}
+
+func (f *failover) Boolean(ctx context.Context, r *evaluation.EvaluationRequest) (*evaluation.BooleanEvaluationResponse, error) {
+	return route(ctx, f, func(client offlipt.Client) (*evaluation.BooleanEvaluationResponse, error) {
+		return client.Boolean(ctx, r)
+	})
+}
+
+func (f *failover) Batch(ctx context.Context, r *evaluation.BatchEvaluationRequest) (*evaluation.BatchEvaluationResponse, error) {
+	return route(ctx, f, func(client offlipt.Client) (*evaluation.BatchEvaluationResponse, error) {
+		return client.Batch(ctx, r)
+	})
+}
@@ -0,0 +1,210 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	flipt "go.flipt.io/flipt/rpc/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+func TestFailover(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		req         = &evaluation.EvaluationRequest{FlagKey: "foo", NamespaceKey: "default", EntityId: entityID}
+		switchovers [][2]string
+	)
+
+	primary := offlipt.NewMockClient(t)
+	secondary := offlipt.NewMockClient(t)
+
+	f := newFailover([]*Service{
+		{address: "grpc://primary:9000", client: primary},
+		{address: "https://secondary", client: secondary},
+	}, nil, []FailoverListener{func(from, to string) {
+		switchovers = append(switchovers, [2]string{from, to})
+	}})
+
+	// the failed call is sent to the secondary, as are the next ones.
+	primary.On("Variant", ctx, req).Return(nil, status.Error(codes.Unavailable, "connection refused")).Once()
+	secondary.On("Variant", ctx, req).Return(&evaluation.VariantEvaluationResponse{VariantKey: "secondary"}, nil).Twice()
+
+	resp, err := f.Variant(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", resp.VariantKey)
+
+	resp, err = f.Variant(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", resp.VariantKey)
+
+	// calls return to the primary once it's found healthy again.
+	primary.On("GetNamespace", mock.Anything, &flipt.GetNamespaceRequest{Key: "default"}).Return(nil, status.Error(codes.Unauthenticated, "unauthenticated"))
+	secondary.On("GetNamespace", mock.Anything, &flipt.GetNamespaceRequest{Key: "default"}).Return(&flipt.Namespace{Key: "default"}, nil)
+
+	f.checkAll(ctx, time.Second)
+
+	// errors other than unavailability are returned without failing over.
+	primary.On("Variant", ctx, req).Return(nil, status.Error(codes.NotFound, "not found")).Once()
+
+	_, err = f.Variant(ctx, req)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.Equal(t, [][2]string{
+		{"grpc://primary:9000", "https://secondary"},
+		{"https://secondary", "grpc://primary:9000"},
+	}, switchovers)
+}
+
+func TestFailover_AllUnavailable(t *testing.T) {
+	var (
+		ctx = context.Background()
+		req = &evaluation.EvaluationRequest{FlagKey: "foo", NamespaceKey: "default", EntityId: entityID}
+	)
+
+	primary := offlipt.NewMockClient(t)
+	secondary := offlipt.NewMockClient(t)
+
+	f := newFailover([]*Service{
+		{address: "grpc://primary:9000", client: primary},
+		{address: "https://secondary", client: secondary},
+	}, nil, nil)
+
+	primary.On("Boolean", ctx, req).Return(nil, status.Error(codes.Unavailable, "connection refused")).Twice()
+	secondary.On("Boolean", ctx, req).Return(nil, status.Error(codes.Unavailable, "connection refused")).Twice()
+
+	// while no endpoint is healthy, every one of them is tried in order.
+	for i := 0; i < 2; i++ {
+		_, err := f.Boolean(ctx, req)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+}
+
+func TestService_WithAddresses(t *testing.T) {
+	s := New(
+		WithAddress("http://localhost:8080"),
+		WithAddresses("grpc://primary:9000", "https://secondary"),
+		WithCertificatePath("foo"),
+		WithDialTimeout(time.Second),
+		WithLocalEvaluation(time.Minute),
+	)
+	assert.Equal(t, "grpc://primary:9000", s.address)
+
+	_, err := s.instance(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, s.failover)
+	assert.Nil(t, s.failover.stop, "health checks should only be checked once started")
+	require.Len(t, s.failover.endpoints, 2)
+	assert.Equal(t, "grpc://primary:9000", s.failover.endpoints[0].service.address)
+	assert.Equal(t, "https://secondary", s.failover.endpoints[1].service.address)
+
+	for _, e := range s.failover.endpoints {
+		assert.Equal(t, "foo", e.service.certificatePath)
+		assert.Equal(t, time.Second, e.service.dialTimeout)
+		assert.Same(t, s.logger, e.service.logger)
+		assert.Nil(t, e.service.addresses)
+		assert.False(t, e.service.local, "flags should be evaluated locally over the failover")
+	}
+
+	require.NoError(t, s.Close())
+	assert.Nil(t, s.failover)
+	assert.Nil(t, s.client)
+
+	s.Start()
+	require.NotNil(t, s.failover)
+	assert.NotNil(t, s.failover.stop)
+	require.NoError(t, s.Close())
+
+	assert.Nil(t, New(WithAddresses("a", "b"), WithAddress("c")).addresses)
+}
+
+func TestFailover_UnreachableGRPCPrimary(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	// nothing listens on the address of the primary.
+	address := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	var (
+		req       = &evaluation.EvaluationRequest{FlagKey: "foo", NamespaceKey: "default", EntityId: entityID}
+		primary   = New(WithAddress(address))
+		secondary = offlipt.NewMockClient(t)
+	)
+
+	f := newFailover([]*Service{primary, {address: "https://secondary", client: secondary}}, nil, nil)
+	f.dialTimeout = 50 * time.Millisecond
+
+	t.Cleanup(func() { require.NoError(t, f.close()) })
+
+	// a call stuck dialing the primary doesn't block the health checks.
+	stuck, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	go func() {
+		_, _ = primary.instance(stuck)
+	}()
+
+	secondary.On("GetNamespace", mock.Anything, &flipt.GetNamespaceRequest{Key: "default"}).Return(&flipt.Namespace{Key: "default"}, nil)
+
+	done := make(chan struct{})
+	go func() {
+		f.checkAll(context.Background(), 100*time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("health check blocked on the primary")
+	}
+
+	assert.Equal(t, []*endpoint{f.endpoints[1]}, f.candidates())
+
+	// calls without a deadline fail over once the dial timeout is exceeded.
+	f.setHealthy(f.endpoints[0], true)
+
+	secondary.On("Variant", mock.Anything, req).Return(&evaluation.VariantEvaluationResponse{VariantKey: "secondary"}, nil).Once()
+
+	resp, err := f.Variant(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", resp.VariantKey)
+	assert.False(t, f.endpoints[0].healthy)
+}
+
+func TestFailover_StateListeners(t *testing.T) {
+	var states []connectivity.State
+
+	s := New(
+		WithAddresses("grpc://primary:9000", "https://secondary"),
+		WithStateListener(func(state connectivity.State) {
+			states = append(states, state)
+		}),
+	)
+
+	f := s.newFailover()
+	f.logger = nil
+
+	primary, secondary := f.endpoints[0], f.endpoints[1]
+
+	secondary.service.setState(connectivity.TransientFailure)
+	assert.Empty(t, states, "states of endpoints on standby should not be reported")
+
+	primary.service.setState(connectivity.Ready)
+	assert.Equal(t, []connectivity.State{connectivity.Ready}, states)
+
+	f.setHealthy(primary, false)
+
+	primary.service.setState(connectivity.TransientFailure)
+	secondary.service.setState(connectivity.Ready)
+	assert.Equal(t, []connectivity.State{connectivity.Ready}, states)
+}
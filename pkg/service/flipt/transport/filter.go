@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+)
+
+// Redacted replaces the values of redacted attributes.
+const Redacted = "[REDACTED]"
+
+// ContextFilter limits the evaluation context attributes sent to Flipt.
+// It applies to the encoded context, so that flattened attributes are
+// referred to by their dot-separated keys.
+type ContextFilter struct {
+	allowed  map[string]struct{}
+	denied   map[string]struct{}
+	redacted map[string]struct{}
+	hashKey  []byte
+}
+
+// FilterOption is a ContextFilter option.
+type FilterOption func(*ContextFilter)
+
+// WithAllowedAttributes only sends the given attributes to Flipt.
+// Every attribute is sent by default.
+func WithAllowedAttributes(keys ...string) FilterOption {
+	return func(f *ContextFilter) {
+		if f.allowed == nil {
+			f.allowed = map[string]struct{}{}
+		}
+
+		for _, key := range keys {
+			f.allowed[key] = struct{}{}
+		}
+	}
+}
+
+// WithDeniedAttributes never sends the given attributes to Flipt,
+// even when they're allowed.
+func WithDeniedAttributes(keys ...string) FilterOption {
+	return func(f *ContextFilter) {
+		for _, key := range keys {
+			f.denied[key] = struct{}{}
+		}
+	}
+}
+
+// WithRedactedAttributes sends the given attributes to Flipt with their value
+// replaced by Redacted, so that only their presence is known.
+func WithRedactedAttributes(keys ...string) FilterOption {
+	return func(f *ContextFilter) {
+		for _, key := range keys {
+			f.redacted[key] = struct{}{}
+		}
+	}
+}
+
+// WithTargetingKeyHashing replaces the targeting key by its HMAC-SHA256 with
+// the given secret key, both as the entity ID and in the context. Flags are
+// consistently bucketed on the hash, while the targeting key never reaches Flipt.
+func WithTargetingKeyHashing(key []byte) FilterOption {
+	return func(f *ContextFilter) {
+		f.hashKey = append([]byte{}, key...)
+	}
+}
+
+// NewContextFilter creates a new ContextFilter.
+func NewContextFilter(opts ...FilterOption) *ContextFilter {
+	f := &ContextFilter{
+		denied:   map[string]struct{}{},
+		redacted: map[string]struct{}{},
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Filter returns the attributes of an encoded context which may be sent to Flipt.
+func (f *ContextFilter) Filter(ec map[string]string) map[string]string {
+	out := make(map[string]string, len(ec))
+
+	for k, v := range ec {
+		if _, ok := f.allowed[k]; f.allowed != nil && !ok {
+			continue
+		}
+
+		if _, ok := f.denied[k]; ok {
+			continue
+		}
+
+		if _, ok := f.redacted[k]; ok {
+			v = Redacted
+		} else if k == of.TargetingKey {
+			v = f.EntityID(v)
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// EntityID returns the entity ID sent to Flipt for a targeting key.
+func (f *ContextFilter) EntityID(targetingKey string) string {
+	if f.hashKey == nil {
+		return targetingKey
+	}
+
+	mac := hmac.New(sha256.New, f.hashKey)
+	mac.Write([]byte(targetingKey))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
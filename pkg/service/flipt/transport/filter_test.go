@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+)
+
+// hashedEntityID is the HMAC-SHA256 of entityID with the key "secret".
+const hashedEntityID = "e9f1f91535398c73105b095ee2be45fa6a26fd4ee56f17b1410ce2145850df42"
+
+func TestContextFilter(t *testing.T) {
+	ec := map[string]string{
+		of.TargetingKey: entityID,
+		"email":         "user@example.com",
+		"ip":            "10.0.0.1",
+		"plan":          "pro",
+	}
+
+	tests := []struct {
+		name     string
+		opts     []FilterOption
+		expected map[string]string
+	}{
+		{
+			name:     "no filter",
+			expected: ec,
+		},
+		{
+			name: "allowed",
+			opts: []FilterOption{WithAllowedAttributes(of.TargetingKey, "plan")},
+			expected: map[string]string{
+				of.TargetingKey: entityID,
+				"plan":          "pro",
+			},
+		},
+		{
+			name: "denied",
+			opts: []FilterOption{WithAllowedAttributes("plan", "email"), WithDeniedAttributes("email", "ip")},
+			expected: map[string]string{
+				"plan": "pro",
+			},
+		},
+		{
+			name: "redacted",
+			opts: []FilterOption{WithRedactedAttributes("email", "ip")},
+			expected: map[string]string{
+				of.TargetingKey: entityID,
+				"email":         Redacted,
+				"ip":            Redacted,
+				"plan":          "pro",
+			},
+		},
+		{
+			name: "hashed targeting key",
+			opts: []FilterOption{WithTargetingKeyHashing([]byte("secret")), WithDeniedAttributes("email", "ip")},
+			expected: map[string]string{
+				of.TargetingKey: hashedEntityID,
+				"plan":          "pro",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewContextFilter(tt.opts...).Filter(ec))
+		})
+	}
+}
+
+func TestEvaluate_ContextFilter(t *testing.T) {
+	mockClient := offlipt.NewMockClient(t)
+	mockClient.On("Variant", mock.Anything, &evaluation.EvaluationRequest{
+		FlagKey:      "foo",
+		NamespaceKey: "foo-namespace",
+		EntityId:     hashedEntityID,
+		Context: map[string]string{
+			of.TargetingKey: hashedEntityID,
+			"email":         Redacted,
+		},
+	}).Return(&evaluation.VariantEvaluationResponse{Match: true, VariantKey: "a"}, nil)
+
+	s := &Service{
+		client: mockClient,
+		filter: NewContextFilter(
+			WithTargetingKeyHashing([]byte("secret")),
+			WithAllowedAttributes(of.TargetingKey, "email"),
+			WithRedactedAttributes("email"),
+		),
+	}
+
+	resp, err := s.Evaluate(context.Background(), "foo-namespace", "foo", map[string]interface{}{
+		of.TargetingKey: entityID,
+		"email":         "user@example.com",
+		"ip":            "10.0.0.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a", resp.VariantKey)
+}
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDGenerator returns the ID of a request to Flipt made on behalf of ctx.
+type RequestIDGenerator func(ctx context.Context) string
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the requests
+// to Flipt made on its behalf, such as the ID of an incoming HTTP request.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+
+	return id, ok && id != ""
+}
+
+// WithRequestIDGenerator sets the generator of the IDs of requests whose context
+// doesn't carry one. By default the ID of the current trace is used, or a random
+// UUID outside of a trace. Request IDs aren't generated when it's nil.
+func WithRequestIDGenerator(generator RequestIDGenerator) Option {
+	return func(s *Service) {
+		s.requestIDGenerator = generator
+	}
+}
+
+// defaultRequestID returns the ID of the trace of ctx, or a random UUID outside of a trace.
+func defaultRequestID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
+	return randomUUID()
+}
+
+// randomUUID returns a random version 4 UUID.
+func randomUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+
+	// version 4, variant 10.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// requestID returns the ID of a request made on behalf of ctx, taken in order from
+// ctx, the legacy requestID attribute of the evaluation context or the generator.
+func (s *Service) requestID(ctx context.Context, evalCtx map[string]interface{}) string {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return id
+	}
+
+	if id, ok := evalCtx[requestID].(string); ok && id != "" {
+		return id
+	}
+
+	if s.requestIDGenerator == nil {
+		return ""
+	}
+
+	return s.requestIDGenerator(ctx)
+}
+
+// echoRequestID sets the request ID of the responses of a batch which lack one,
+// so that it's reported whether or not Flipt echoes it.
+func echoRequestID(resp *evaluation.BatchEvaluationResponse, id string) {
+	if resp.RequestId == "" {
+		resp.RequestId = id
+	}
+
+	for _, r := range resp.Responses {
+		if v := r.GetVariantResponse(); v != nil && v.RequestId == "" {
+			v.RequestId = id
+		}
+
+		if b := r.GetBooleanResponse(); b != nil && b.RequestId == "" {
+			b.RequestId = id
+		}
+	}
+}
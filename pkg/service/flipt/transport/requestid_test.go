@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	of "github.com/open-feature/go-sdk/pkg/openfeature"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestID(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		evalCtx = map[string]interface{}{of.TargetingKey: entityID, "requestID": "legacy"}
+		s       = New(WithRequestIDGenerator(func(context.Context) string { return "generated" }))
+	)
+
+	assert.Equal(t, "from-context", s.requestID(ContextWithRequestID(ctx, "from-context"), evalCtx))
+	assert.Equal(t, "legacy", s.requestID(ctx, evalCtx))
+	assert.Equal(t, "generated", s.requestID(ctx, map[string]interface{}{of.TargetingKey: entityID}))
+
+	assert.Empty(t, New(WithRequestIDGenerator(nil)).requestID(ctx, nil))
+
+	_, ok := RequestIDFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestDefaultRequestID(t *testing.T) {
+	id := defaultRequestID(context.Background())
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.NotEqual(t, id, defaultRequestID(context.Background()))
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", defaultRequestID(ctx))
+}
+
+func TestBatch_GeneratedRequestID(t *testing.T) {
+	var generated int
+
+	ec := map[string]string{of.TargetingKey: entityID}
+
+	mockClient := offlipt.NewMockClient(t)
+	mockClient.EXPECT().Batch(mock.Anything, &evaluation.BatchEvaluationRequest{
+		RequestId: "generated",
+		Requests: []*evaluation.EvaluationRequest{
+			{FlagKey: "foo", NamespaceKey: "foo-namespace", RequestId: "generated", EntityId: entityID, Context: ec},
+			{FlagKey: "bar", NamespaceKey: "foo-namespace", RequestId: "generated", EntityId: entityID, Context: ec},
+		},
+	}).Return(&evaluation.BatchEvaluationResponse{
+		Responses: []*evaluation.EvaluationResponse{
+			{
+				Type: evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+				Response: &evaluation.EvaluationResponse_VariantResponse{
+					VariantResponse: &evaluation.VariantEvaluationResponse{Match: true, VariantKey: "a"},
+				},
+			},
+		},
+	}, nil)
+
+	s := New(WithClient(mockClient), WithRequestIDGenerator(func(context.Context) string {
+		generated++
+
+		return "generated"
+	}))
+
+	resp, err := s.Batch(context.Background(), "foo-namespace", []string{"foo", "bar"}, map[string]interface{}{of.TargetingKey: entityID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	assert.Equal(t, "generated", resp.RequestId)
+	assert.Equal(t, "generated", resp.Responses[0].GetVariantResponse().RequestId)
+}
@@ -0,0 +1,195 @@
+package transport
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures how calls to Flipt failing with a transient error are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a call, including the first one.
+	// Calls are not retried when it is one or less.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, except for delays requested by Flipt with Retry-After.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt.
+	Multiplier float64
+	// Jitter is the fraction of each delay, between 0 and 1, which is randomized.
+	Jitter float64
+	// RetryableCodes are the gRPC status codes which are retried.
+	RetryableCodes []codes.Code
+	// RetryableStatuses are the HTTP response status codes which are retried.
+	RetryableStatuses []int
+}
+
+// DefaultRetryPolicy returns a policy making up to three attempts of calls
+// failing because Flipt is unavailable or rate limiting.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableCodes: []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+		RetryableStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy retries calls to Flipt failing with a transient error according
+// to the given policy, over both gRPC and HTTP. Retries never outlast the
+// deadline of the context of a call. Calls are not retried by default.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *Service) {
+		s.retryPolicy = &policy
+	}
+}
+
+// backoff returns the delay before the given retry, starting at one.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(math.Max(p.Multiplier, 1), float64(retry-1))
+	if p.MaxBackoff > 0 {
+		d = math.Min(d, float64(p.MaxBackoff))
+	}
+
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		d -= d * jitter * rand.Float64() //nolint:gosec
+	}
+
+	return time.Duration(d)
+}
+
+func (p RetryPolicy) retryableCode(code codes.Code) bool {
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, s := range p.RetryableStatuses {
+		if s == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wait waits for the given delay. It returns false without waiting when the
+// context would be done before the delay has passed.
+func wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// unaryRetryInterceptor returns a gRPC interceptor retrying calls failing with a retryable code.
+func unaryRetryInterceptor(policy RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.retryableCode(status.Code(err)) {
+				return err
+			}
+
+			if !wait(ctx, policy.backoff(attempt)) {
+				return err
+			}
+		}
+	}
+}
+
+// retryTransport is an http.RoundTripper retrying requests which fail or
+// receive a response with a retryable status.
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+
+		retryable := err != nil || t.policy.retryableStatus(resp.StatusCode)
+		if !retryable || attempt >= t.policy.MaxAttempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay := t.policy.backoff(attempt)
+		if err == nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+		}
+
+		if !wait(req.Context(), delay) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a
+// response with status 429 or 503.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
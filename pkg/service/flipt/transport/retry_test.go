@@ -0,0 +1,197 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+
+	return policy
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		retryAfter       string
+		timeout          time.Duration
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{
+			name:             "success",
+			statuses:         []int{http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retried",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "max attempts",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "not retryable",
+			statuses:         []int{http.StatusBadRequest, http.StatusOK},
+			expectedStatus:   http.StatusBadRequest,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retry after",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "0",
+			expectedStatus:   http.StatusOK,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "retry after exceeds deadline",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "10",
+			timeout:          time.Second,
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				attempts int
+				bodies   []string
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(b))
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(tt.statuses[attempts])
+				attempts++
+			}))
+			defer server.Close()
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+
+			client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport, policy: testRetryPolicy()}}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedAttempts, attempts)
+
+			for _, body := range bodies {
+				assert.Equal(t, "body", body)
+			}
+		})
+	}
+}
+
+func TestUnaryRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name             string
+		errs             []error
+		expectedCode     codes.Code
+		expectedAttempts int
+	}{
+		{
+			name:             "retried",
+			errs:             []error{status.Error(codes.Unavailable, "unavailable"), nil},
+			expectedCode:     codes.OK,
+			expectedAttempts: 2,
+		},
+		{
+			name: "max attempts",
+			errs: []error{
+				status.Error(codes.Unavailable, "unavailable"),
+				status.Error(codes.ResourceExhausted, "rate limited"),
+				status.Error(codes.Unavailable, "unavailable"),
+				nil,
+			},
+			expectedCode:     codes.Unavailable,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "not retryable",
+			errs:             []error{status.Error(codes.NotFound, "not found"), nil},
+			expectedCode:     codes.NotFound,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int
+
+			invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+				err := tt.errs[attempts]
+				attempts++
+
+				return err
+			}
+
+			err := unaryRetryInterceptor(testRetryPolicy())(context.Background(), "/flipt.evaluation.EvaluationService/Variant", nil, nil, nil, invoker)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, time.Second, policy.backoff(5))
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		d := policy.backoff(1)
+		assert.GreaterOrEqual(t, d, 50*time.Millisecond)
+		assert.LessOrEqual(t, d, 100*time.Millisecond)
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	assert.Len(t, New().interceptors(), 1)
+	assert.Len(t, New(WithRetryPolicy(DefaultRetryPolicy())).interceptors(), 2)
+}
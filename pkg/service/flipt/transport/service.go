@@ -4,14 +4,20 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	of "github.com/open-feature/go-sdk/pkg/openfeature"
 	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
 	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/util"
 	flipt "go.flipt.io/flipt/rpc/flipt"
 	"go.flipt.io/flipt/rpc/flipt/evaluation"
@@ -19,9 +25,15 @@ import (
 	sdkgrpc "go.flipt.io/flipt/sdk/go/grpc"
 	sdkhttp "go.flipt.io/flipt/sdk/go/http"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -29,23 +41,58 @@ const (
 	defaultAddr = "http://localhost:8080"
 )
 
+var errClosed = errors.New("service closed")
+
 // Service is a Transport service.
 type Service struct {
-	client            offlipt.Client
-	address           string
-	certificatePath   string
-	unaryInterceptors []grpc.UnaryClientInterceptor
-	once              sync.Once
-	tokenProvider     sdk.ClientTokenProvider
+	client             offlipt.Client
+	conn               *grpc.ClientConn
+	address            string
+	certificatePath    string
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	mu                 sync.Mutex
+	tokenProvider      sdk.ClientTokenProvider
+	stopWatch          context.CancelFunc
+	dialing            chan struct{}
+	local              bool
+	refreshInterval    time.Duration
+	refreshListener    func(err error)
+	propagators        propagation.TextMapPropagator
+	tracerProvider     trace.TracerProvider
+	retryPolicy        *RetryPolicy
+	requestIDGenerator RequestIDGenerator
+	entityIDResolver   EntityIDResolver
+	encoder            *ContextEncoder
+	filter             *ContextFilter
+
+	addresses           []string
+	healthCheckInterval time.Duration
+	failoverListeners   []FailoverListener
+	logger              *slog.Logger
+	failover            *failover
+	dialTimeout         time.Duration
+
+	stateMu        sync.Mutex
+	state          connectivity.State
+	stateListeners []StateListener
+
+	// opts configure the services of each of the addresses.
+	opts []Option
+	// closed records that the service was closed while dialing.
+	closed bool
 }
 
 // Option is a service option.
 type Option func(*Service)
 
+// StateListener is called whenever the observed state of the connection to Flipt changes.
+type StateListener func(state connectivity.State)
+
 // WithAddress sets the address for the remote Flipt gRPC API.
 func WithAddress(address string) Option {
 	return func(s *Service) {
 		s.address = address
+		s.addresses = nil
 	}
 }
 
@@ -58,9 +105,26 @@ func WithCertificatePath(certificatePath string) Option {
 
 // WithUnaryClientInterceptor sets the provided unary client interceptors
 // to be applied to the established gRPC client connection.
+// They replace the default OpenTelemetry interceptor.
 func WithUnaryClientInterceptor(unaryInterceptors ...grpc.UnaryClientInterceptor) Option {
 	return func(s *Service) {
-		s.unaryInterceptors = unaryInterceptors
+		s.unaryInterceptors = append([]grpc.UnaryClientInterceptor{}, unaryInterceptors...)
+	}
+}
+
+// WithPropagators sets the propagators used to inject the trace context into
+// requests to Flipt, over both gRPC and HTTP. The global propagators are used by default.
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(s *Service) {
+		s.propagators = propagators
+	}
+}
+
+// WithTracerProvider sets the provider of the tracer recording the spans of
+// requests to Flipt, over both gRPC and HTTP. The global tracer provider is used by default.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(s *Service) {
+		s.tracerProvider = tracerProvider
 	}
 }
 
@@ -72,25 +136,125 @@ func WithClientTokenProvider(tokenProvider sdk.ClientTokenProvider) Option {
 	}
 }
 
+// WithStateListener registers listeners to be notified of connectivity
+// state transitions. For gRPC these follow the state of the client
+// connection, for HTTP they are derived from the outcome of each request.
+func WithStateListener(listeners ...StateListener) Option {
+	return func(s *Service) {
+		s.stateListeners = append(s.stateListeners, listeners...)
+	}
+}
+
+// WithContextEncoder sets the encoder converting evaluation contexts to the
+// string values sent to Flipt. NewContextEncoder() is used by default.
+func WithContextEncoder(encoder *ContextEncoder) Option {
+	return func(s *Service) {
+		s.encoder = encoder
+	}
+}
+
+// WithContextFilter filters the evaluation context attributes sent to Flipt.
+// Every attribute is sent verbatim by default.
+func WithContextFilter(filter *ContextFilter) Option {
+	return func(s *Service) {
+		s.filter = filter
+	}
+}
+
+// WithLocalEvaluation evaluates flags in-process instead of calling the
+// evaluation API. The state of each namespace is downloaded from Flipt on
+// first use and refreshed at the given interval.
+func WithLocalEvaluation(refreshInterval time.Duration) Option {
+	return func(s *Service) {
+		s.local = true
+		s.refreshInterval = refreshInterval
+	}
+}
+
+// WithRefreshListener registers a listener which is called with the outcome of
+// every background refresh of the local evaluation state.
+func WithRefreshListener(listener func(err error)) Option {
+	return func(s *Service) {
+		s.refreshListener = listener
+	}
+}
+
+// WithClient sets the Flipt client used by the service instead of
+// connecting to the configured address.
+func WithClient(client offlipt.Client) Option {
+	return func(s *Service) {
+		s.client = client
+	}
+}
+
 // New creates a new Transport service.
 func New(opts ...Option) *Service {
 	s := &Service{
-		address: defaultAddr,
-		unaryInterceptors: []grpc.UnaryClientInterceptor{
-			// by default this establishes the otel.TextMapPropagator
-			// registers to the otel package.
-			otelgrpc.UnaryClientInterceptor(),
-		},
+		address:             defaultAddr,
+		healthCheckInterval: defaultHealthCheckInterval,
+		dialTimeout:         defaultDialTimeout,
+		logger:              slog.Default(),
+		requestIDGenerator:  defaultRequestID,
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	s.opts = opts
+
+	if s.unaryInterceptors == nil {
+		var otelOpts []otelgrpc.Option
+		if s.propagators != nil {
+			otelOpts = append(otelOpts, otelgrpc.WithPropagators(s.propagators))
+		}
+
+		if s.tracerProvider != nil {
+			otelOpts = append(otelOpts, otelgrpc.WithTracerProvider(s.tracerProvider))
+		}
+
+		s.unaryInterceptors = []grpc.UnaryClientInterceptor{
+			// by default this establishes the otel.TextMapPropagator
+			// registers to the otel package.
+			otelgrpc.UnaryClientInterceptor(otelOpts...),
+		}
+	}
+
 	return s
 }
 
-func (s *Service) connect() (*grpc.ClientConn, error) {
+// httpClient returns the HTTP client used to call Flipt, instrumented like the gRPC connection.
+func (s *Service) httpClient() *http.Client {
+	var opts []otelhttp.Option
+	if s.propagators != nil {
+		opts = append(opts, otelhttp.WithPropagators(s.propagators))
+	}
+
+	if s.tracerProvider != nil {
+		opts = append(opts, otelhttp.WithTracerProvider(s.tracerProvider))
+	}
+
+	var rt http.RoundTripper = otelhttp.NewTransport(http.DefaultTransport, opts...)
+	if s.retryPolicy != nil {
+		rt = &retryTransport{next: rt, policy: *s.retryPolicy}
+	}
+
+	return &http.Client{
+		Transport: rt,
+	}
+}
+
+// interceptors returns the unary interceptors of the gRPC connection.
+// Retries come first, so that every attempt passes through the other interceptors.
+func (s *Service) interceptors() []grpc.UnaryClientInterceptor {
+	if s.retryPolicy == nil {
+		return s.unaryInterceptors
+	}
+
+	return append([]grpc.UnaryClientInterceptor{unaryRetryInterceptor(*s.retryPolicy)}, s.unaryInterceptors...)
+}
+
+func (s *Service) connect(ctx context.Context) (*grpc.ClientConn, error) {
 	var (
 		err         error
 		credentials = insecure.NewCredentials()
@@ -110,11 +274,12 @@ func (s *Service) connect() (*grpc.ClientConn, error) {
 		address = "passthrough:///" + s.address
 	}
 
-	conn, err := grpc.Dial(
+	conn, err := grpc.DialContext(
+		ctx,
 		address,
 		grpc.WithTransportCredentials(credentials),
 		grpc.WithBlock(),
-		grpc.WithChainUnaryInterceptor(s.unaryInterceptors...),
+		grpc.WithChainUnaryInterceptor(s.interceptors()...),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("dialing %w", err)
@@ -123,58 +288,262 @@ func (s *Service) connect() (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
-func (s *Service) instance() (offlipt.Client, error) {
+func (s *Service) instance(ctx context.Context) (offlipt.Client, error) {
 	type fclient struct {
 		*sdk.Flipt
 		*sdk.Evaluation
 	}
 
+	s.mu.Lock()
+
+	for s.client == nil && s.dialing != nil {
+		// another call is dialing Flipt, wait for it without holding the lock.
+		dialing := s.dialing
+		s.mu.Unlock()
+
+		select {
+		case <-dialing:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("connecting %w", ctx.Err())
+		}
+
+		s.mu.Lock()
+	}
+
 	if s.client != nil {
+		defer s.mu.Unlock()
+
 		return s.client, nil
 	}
 
-	var err error
+	if len(s.addresses) > 1 {
+		defer s.mu.Unlock()
 
-	s.once.Do(func() {
-		u, uerr := url.Parse(s.address)
-		if uerr != nil {
-			err = fmt.Errorf("connecting %w", uerr)
-		}
+		s.failover = s.newFailover()
+		s.client = s.wrap(s.failover)
+
+		return s.client, nil
+	}
+
+	u, err := url.Parse(s.address)
+	if err != nil {
+		s.mu.Unlock()
+
+		return nil, fmt.Errorf("connecting %w", err)
+	}
 
-		opts := []sdk.Option{}
+	opts := []sdk.Option{}
 
-		if s.tokenProvider != nil {
-			opts = append(opts, sdk.WithClientTokenProvider(s.tokenProvider))
+	if s.tokenProvider != nil {
+		opts = append(opts, sdk.WithClientTokenProvider(s.tokenProvider))
+	}
+
+	if u.Scheme == "https" || u.Scheme == "http" {
+		defer s.mu.Unlock()
+
+		hclient := sdk.New(sdkhttp.NewTransport(s.address, sdkhttp.WithHTTPClient(s.httpClient())), opts...)
+		s.client = s.wrap(&fclient{
+			hclient.Flipt(),
+			hclient.Evaluation(),
+		})
+
+		return s.client, nil
+	}
+
+	// dialing blocks until Flipt is reachable, so it's done without holding the lock.
+	dialing := make(chan struct{})
+	s.dialing = dialing
+	s.mu.Unlock()
+
+	conn, err := s.connect(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dialing = nil
+	close(dialing)
+
+	closed := s.closed
+	s.closed = false
+
+	if err != nil {
+		s.observe(err)
+
+		return nil, fmt.Errorf("connecting %w", err)
+	}
+
+	if closed {
+		// the service was closed while dialing, the connection must not outlive it.
+		return nil, fmt.Errorf("connecting %w", errors.Join(errClosed, conn.Close()))
+	}
+
+	wctx, cancel := context.WithCancel(context.Background())
+	go s.watch(wctx, conn)
+
+	gclient := sdk.New(sdkgrpc.NewTransport(conn), opts...)
+	s.conn = conn
+	s.stopWatch = cancel
+	s.client = s.wrap(&fclient{
+		gclient.Flipt(),
+		gclient.Evaluation(),
+	})
+
+	return s.client, nil
+}
+
+// wrap returns the client which evaluates flags locally on top of the remote
+// client when local evaluation is enabled.
+func (s *Service) wrap(client interface {
+	offlipt.Client
+	local.Lister
+}) offlipt.Client {
+	if !s.local {
+		return client
+	}
+
+	return local.NewClient(local.NewAPILoader(client),
+		local.WithRefreshInterval(s.refreshInterval),
+		local.WithRefreshListener(s.refreshListener),
+	)
+}
+
+// Close closes the underlying gRPC connection, if one has been established,
+// and stops refreshing local evaluation state and checking the health of addresses.
+// A connection being dialed is closed once established.
+// A subsequent call on the service dials Flipt again.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dialing != nil {
+		s.closed = true
+	}
+
+	if closer, ok := s.client.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("closing %w", err)
 		}
+	}
 
-		hclient := sdk.New(sdkhttp.NewTransport(s.address), opts...)
-		if u.Scheme == "https" || u.Scheme == "http" {
-			s.client = &fclient{
-				hclient.Flipt(),
-				hclient.Evaluation(),
-			}
+	if s.failover != nil {
+		err := s.failover.close()
 
-			return
+		s.failover = nil
+		s.client = nil
+
+		if err != nil {
+			return fmt.Errorf("closing %w", err)
 		}
 
-		conn, cerr := s.connect()
-		if cerr != nil {
-			err = fmt.Errorf("connecting %w", cerr)
+		return nil
+	}
+
+	if s.conn == nil {
+		if s.local {
+			s.client = nil
 		}
 
-		gclient := sdk.New(sdkgrpc.NewTransport(conn), opts...)
-		s.client = &fclient{
-			gclient.Flipt(),
-			gclient.Evaluation(),
+		return nil
+	}
+
+	s.stopWatch()
+
+	err := s.conn.Close()
+
+	s.conn = nil
+	s.client = nil
+	s.stopWatch = nil
+
+	if err != nil {
+		return fmt.Errorf("closing %w", err)
+	}
+
+	return nil
+}
+
+// watch reports the state transitions of the gRPC connection until ctx is done.
+func (s *Service) watch(ctx context.Context, conn *grpc.ClientConn) {
+	for {
+		state := conn.GetState()
+		s.setState(state)
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return
 		}
+	}
+}
+
+// observe records the connectivity implied by the outcome of a call to Flipt.
+// Errors caused by the caller giving up on a request say nothing about
+// the health of Flipt and are ignored.
+func (s *Service) observe(err error) {
+	if err == nil {
+		s.setState(connectivity.Ready)
+
+		return
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		s.setState(connectivity.TransientFailure)
+
+		return
+	}
+
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded:
+	case codes.Unavailable:
+		s.setState(connectivity.TransientFailure)
+	default:
+		s.setState(connectivity.Ready)
+	}
+}
+
+func (s *Service) setState(state connectivity.State) {
+	s.stateMu.Lock()
+
+	if s.state == state {
+		s.stateMu.Unlock()
+
+		return
+	}
+
+	s.state = state
+	listeners := s.stateListeners
+
+	s.stateMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(state)
+	}
+}
+
+// GetNamespace returns a namespace if it exists for the given namespace key.
+func (s *Service) GetNamespace(ctx context.Context, namespaceKey string) (*flipt.Namespace, error) {
+	conn, err := s.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ns, err := conn.GetNamespace(ctx, &flipt.GetNamespaceRequest{
+		Key: namespaceKey,
 	})
+	s.observe(err)
+
+	if err != nil {
+		return nil, util.GRPCToOpenFeatureError(err)
+	}
 
-	return s.client, err
+	return ns, nil
 }
 
 // GetFlag returns a flag if it exists for the given namespace/flag key pair.
 func (s *Service) GetFlag(ctx context.Context, namespaceKey, flagKey string) (*flipt.Flag, error) {
-	conn, err := s.instance()
+	conn, err := s.instance(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +552,8 @@ func (s *Service) GetFlag(ctx context.Context, namespaceKey, flagKey string) (*f
 		Key:          flagKey,
 		NamespaceKey: namespaceKey,
 	})
+	s.observe(err)
+
 	if err != nil {
 		return nil, util.GRPCToOpenFeatureError(err)
 	}
@@ -190,65 +561,204 @@ func (s *Service) GetFlag(ctx context.Context, namespaceKey, flagKey string) (*f
 	return flag, nil
 }
 
-// Boolean evaluates a boolean type flag with the given context and namespace/flag key pair.
-func (s *Service) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
-	if evalCtx == nil {
-		return nil, of.NewInvalidContextResolutionError("evalCtx is nil")
+// ListFlags returns all flags in the given namespace.
+func (s *Service) ListFlags(ctx context.Context, namespaceKey string) ([]*flipt.Flag, error) {
+	conn, err := s.instance(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	ec := convertMapInterface(evalCtx)
+	var (
+		flags     []*flipt.Flag
+		pageToken string
+	)
 
-	targetingKey := ec[of.TargetingKey]
-	if targetingKey == "" {
-		return nil, of.NewTargetingKeyMissingResolutionError("targetingKey is missing")
+	for {
+		list, err := conn.ListFlags(ctx, &flipt.ListFlagRequest{
+			NamespaceKey: namespaceKey,
+			PageToken:    pageToken,
+		})
+		s.observe(err)
+
+		if err != nil {
+			return nil, util.GRPCToOpenFeatureError(err)
+		}
+
+		flags = append(flags, list.Flags...)
+
+		if list.NextPageToken == "" {
+			return flags, nil
+		}
+
+		pageToken = list.NextPageToken
 	}
+}
 
-	conn, err := s.instance()
+// Snapshot returns the complete evaluation state of the given namespace, including
+// the rules and rollouts of its flags. It's the snapshot flags are evaluated
+// against when they're evaluated locally.
+func (s *Service) Snapshot(ctx context.Context, namespaceKey string) (*local.Snapshot, error) {
+	conn, err := s.instance(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	ber, err := conn.Boolean(ctx, &evaluation.EvaluationRequest{FlagKey: flagKey, NamespaceKey: namespaceKey, EntityId: targetingKey, RequestId: ec[requestID], Context: ec})
+	var snap *local.Snapshot
+
+	switch c := conn.(type) {
+	case interface {
+		Snapshot(ctx context.Context, namespaceKey string) (*local.Snapshot, error)
+	}:
+		snap, err = c.Snapshot(ctx, namespaceKey)
+	case local.Lister:
+		snap, err = local.NewAPILoader(c).Load(ctx, namespaceKey)
+		s.observe(err)
+	default:
+		return nil, of.NewGeneralResolutionError("snapshots are not supported by the client")
+	}
+
 	if err != nil {
 		return nil, util.GRPCToOpenFeatureError(err)
 	}
 
+	return snap, nil
+}
+
+// Boolean evaluates a boolean type flag with the given context and namespace/flag key pair.
+func (s *Service) Boolean(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.BooleanEvaluationResponse, error) {
+	req, err := s.request(ctx, namespaceKey, flagKey, evalCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ber, err := conn.Boolean(ctx, req)
+	s.observe(err)
+
+	if err != nil {
+		return nil, util.GRPCToOpenFeatureError(err)
+	}
+
+	if ber.RequestId == "" {
+		ber.RequestId = req.RequestId
+	}
+
 	return ber, nil
 }
 
 // Evaluate evaluates a variant type flag with the given context and namespace/flag key pair.
 func (s *Service) Evaluate(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.VariantEvaluationResponse, error) {
-	if evalCtx == nil {
-		return nil, of.NewInvalidContextResolutionError("evalCtx is nil")
+	req, err := s.request(ctx, namespaceKey, flagKey, evalCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.instance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := conn.Variant(ctx, req)
+	s.observe(err)
+
+	if err != nil {
+		return nil, util.GRPCToOpenFeatureError(err)
+	}
+
+	if resp.RequestId == "" {
+		resp.RequestId = req.RequestId
 	}
 
-	ec := convertMapInterface(evalCtx)
+	return resp, nil
+}
+
+// Batch evaluates the given flags of a namespace with the same context in a single request.
+// Responses are in the order of the flag keys; flags which don't exist are
+// reported as error responses rather than failing the batch.
+func (s *Service) Batch(ctx context.Context, namespaceKey string, flagKeys []string, evalCtx map[string]interface{}) (*evaluation.BatchEvaluationResponse, error) {
+	// every request of the batch shares its ID.
+	ctx = ContextWithRequestID(ctx, s.requestID(ctx, evalCtx))
 
-	targetingKey := ec[of.TargetingKey]
-	if targetingKey == "" {
-		return nil, of.NewTargetingKeyMissingResolutionError("targetingKey is missing")
+	batch := &evaluation.BatchEvaluationRequest{
+		Requests: make([]*evaluation.EvaluationRequest, 0, len(flagKeys)),
 	}
 
-	conn, err := s.instance()
+	for _, flagKey := range flagKeys {
+		req, err := s.request(ctx, namespaceKey, flagKey, evalCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		batch.RequestId = req.RequestId
+		batch.Requests = append(batch.Requests, req)
+	}
+
+	conn, err := s.instance(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := conn.Variant(ctx, &evaluation.EvaluationRequest{FlagKey: flagKey, NamespaceKey: namespaceKey, EntityId: targetingKey, RequestId: ec[requestID], Context: ec})
+	resp, err := conn.Batch(ctx, batch)
+	s.observe(err)
+
 	if err != nil {
 		return nil, util.GRPCToOpenFeatureError(err)
 	}
 
+	echoRequestID(resp, batch.RequestId)
+
 	return resp, nil
 }
 
-func convertMapInterface(m map[string]interface{}) map[string]string {
-	ee := make(map[string]string)
-	for k, v := range m {
-		ee[k] = fmt.Sprintf("%v", v)
+// request builds the evaluation request of a flag from an evaluation context.
+// The request ID is sent as the ID of the request rather than as a context attribute.
+func (s *Service) request(ctx context.Context, namespaceKey, flagKey string, evalCtx map[string]interface{}) (*evaluation.EvaluationRequest, error) {
+	if evalCtx == nil {
+		return nil, of.NewInvalidContextResolutionError("evalCtx is nil")
+	}
+
+	encoder := s.encoder
+	if encoder == nil {
+		encoder = NewContextEncoder()
+	}
+
+	ec, err := encoder.Encode(evalCtx)
+	if err != nil {
+		return nil, of.NewInvalidContextResolutionError(err.Error())
+	}
+
+	delete(ec, requestID)
+
+	entityID := ec[of.TargetingKey]
+	if entityID == "" {
+		resolver := s.entityIDResolver
+		if resolver == nil {
+			resolver = StrictEntityID()
+		}
+
+		if entityID, err = resolver(ec); err != nil {
+			return nil, err
+		}
+	}
+
+	req := &evaluation.EvaluationRequest{
+		FlagKey:      flagKey,
+		NamespaceKey: namespaceKey,
+		EntityId:     entityID,
+		RequestId:    s.requestID(ctx, evalCtx),
+		Context:      ec,
+	}
+
+	if s.filter != nil {
+		req.EntityId = s.filter.EntityID(entityID)
+		req.Context = s.filter.Filter(ec)
 	}
 
-	return ee
+	return req, nil
 }
 
 func loadTLSCredentials(serverCertPath string) (credentials.TransportCredentials, error) {
@@ -2,16 +2,28 @@ package transport
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	of "github.com/open-feature/go-sdk/pkg/openfeature"
 	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	offlipt "go.flipt.io/flipt-openfeature-provider/pkg/service/flipt"
+	"go.flipt.io/flipt-openfeature-provider/pkg/service/flipt/local"
 	flipt "go.flipt.io/flipt/rpc/flipt"
 	"go.flipt.io/flipt/rpc/flipt/evaluation"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/status"
 )
 
@@ -24,32 +36,31 @@ func TestNew(t *testing.T) {
 	tests := []struct {
 		name     string
 		opts     []Option
-		expected Service
+		expected *Service
 	}{
 		{
 			name: "default",
-			expected: Service{
+			expected: &Service{
 				address: "http://localhost:8080",
 			},
 		},
 		{
 			name: "with host",
 			opts: []Option{WithAddress("foo:9000")},
-			expected: Service{
+			expected: &Service{
 				address: "foo:9000",
 			},
 		},
 		{
 			name: "with certificate path",
 			opts: []Option{WithCertificatePath("foo")},
-			expected: Service{
+			expected: &Service{
 				address:         "http://localhost:8080",
 				certificatePath: "foo",
 			},
 		},
 	}
 
-	//nolint (copylocks)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := New(tt.opts...)
@@ -60,6 +71,135 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_UnaryClientInterceptors(t *testing.T) {
+	assert.Len(t, New().unaryInterceptors, 1)
+	assert.Empty(t, New(WithUnaryClientInterceptor()).unaryInterceptors)
+}
+
+func TestHTTPClientTracing(t *testing.T) {
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+
+	s := New(
+		WithPropagators(propagation.TraceContext{}),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := s.httpClient().Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Contains(t, traceparent, spans[0].SpanContext().TraceID().String())
+}
+
+func TestGetNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+		expected    *flipt.Namespace
+	}{
+		{
+			name: "success",
+			expected: &flipt.Namespace{
+				Key: "foo-namespace",
+			},
+		},
+		{
+			name:        "namespace not found",
+			err:         status.Error(codes.NotFound, `namespace "foo-namespace" not found`),
+			expectedErr: of.NewFlagNotFoundResolutionError(`namespace "foo-namespace" not found`),
+		},
+		{
+			name:        "unavailable",
+			err:         status.Error(codes.Unavailable, "connection refused"),
+			expectedErr: of.NewProviderNotReadyResolutionError("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := offlipt.NewMockClient(t)
+
+			mockClient.On("GetNamespace", mock.Anything, &flipt.GetNamespaceRequest{
+				Key: "foo-namespace",
+			}).Return(tt.expected, tt.err)
+
+			s := &Service{
+				client: mockClient,
+			}
+
+			actual, err := s.GetNamespace(context.Background(), "foo-namespace")
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCloseWithoutConnection(t *testing.T) {
+	s := New(WithAddress("http://localhost:8080"))
+
+	assert.NoError(t, s.Close())
+}
+
+func TestCloseWhileDialing(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	t.Cleanup(server.Stop)
+
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	require.NoError(t, err)
+
+	s := New(WithAddress(net.JoinHostPort("localhost", port)))
+
+	errs := make(chan error)
+	go func() {
+		_, err := s.instance(context.Background())
+		errs <- err
+	}()
+
+	// the dial blocks until the server accepts connections.
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.dialing != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, s.Close())
+
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	assert.ErrorIs(t, <-errs, errClosed)
+	assert.Nil(t, s.conn)
+	assert.Nil(t, s.client)
+	assert.Nil(t, s.stopWatch)
+
+	_, err = s.instance(context.Background())
+	require.NoError(t, err, "the service should dial again once closed")
+	require.NoError(t, s.Close())
+}
+
 func TestGetFlag(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -110,6 +250,54 @@ func TestGetFlag(t *testing.T) {
 	}
 }
 
+func TestListFlags(t *testing.T) {
+	mockClient := offlipt.NewMockClient(t)
+
+	mockClient.On("ListFlags", mock.Anything, &flipt.ListFlagRequest{
+		NamespaceKey: "foo-namespace",
+	}).Return(&flipt.FlagList{
+		Flags:         []*flipt.Flag{{Key: "foo"}},
+		NextPageToken: "next",
+	}, nil)
+
+	mockClient.On("ListFlags", mock.Anything, &flipt.ListFlagRequest{
+		NamespaceKey: "foo-namespace",
+		PageToken:    "next",
+	}).Return(&flipt.FlagList{
+		Flags: []*flipt.Flag{{Key: "bar"}},
+	}, nil)
+
+	s := &Service{
+		client: mockClient,
+	}
+
+	actual, err := s.ListFlags(context.Background(), "foo-namespace")
+	assert.NoError(t, err)
+	assert.Equal(t, []*flipt.Flag{{Key: "foo"}, {Key: "bar"}}, actual)
+}
+
+func TestStateListener(t *testing.T) {
+	var states []connectivity.State
+
+	s := New(WithStateListener(func(state connectivity.State) {
+		states = append(states, state)
+	}))
+
+	s.observe(nil)
+	s.observe(status.Error(codes.NotFound, "not found"))
+	s.observe(status.Error(codes.Unavailable, "unavailable"))
+	s.observe(status.Error(codes.Unavailable, "unavailable"))
+	s.observe(context.Canceled)
+	s.observe(status.Error(codes.DeadlineExceeded, "deadline exceeded"))
+	s.observe(nil)
+
+	assert.Equal(t, []connectivity.State{
+		connectivity.Ready,
+		connectivity.TransientFailure,
+		connectivity.Ready,
+	}, states)
+}
+
 func TestEvaluate_NonBoolean(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -146,7 +334,6 @@ func TestEvaluate_NonBoolean(t *testing.T) {
 				RequestId:    reqID,
 				EntityId:     entityID,
 				Context: map[string]string{
-					"requestID":    reqID,
 					"targetingKey": entityID,
 				},
 			}).Return(tt.expected, tt.err)
@@ -185,7 +372,6 @@ func TestEvaluate_Boolean(t *testing.T) {
 		RequestId:    reqID,
 		EntityId:     entityID,
 		Context: map[string]string{
-			"requestID":    reqID,
 			"targetingKey": entityID,
 		},
 	}).Return(ber, nil)
@@ -204,6 +390,76 @@ func TestEvaluate_Boolean(t *testing.T) {
 	assert.False(t, actual.Enabled, "match value should be false")
 }
 
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		expectedErr error
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:        "unavailable",
+			err:         status.Error(codes.Unavailable, "unavailable"),
+			expectedErr: of.NewProviderNotReadyResolutionError("unavailable"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec := map[string]string{
+				"targetingKey": entityID,
+			}
+
+			expected := &evaluation.BatchEvaluationResponse{
+				RequestId: reqID,
+				Responses: []*evaluation.EvaluationResponse{
+					{
+						Type: evaluation.EvaluationResponseType_BOOLEAN_EVALUATION_RESPONSE_TYPE,
+						Response: &evaluation.EvaluationResponse_BooleanResponse{
+							BooleanResponse: &evaluation.BooleanEvaluationResponse{Enabled: true},
+						},
+					},
+					{
+						Type: evaluation.EvaluationResponseType_VARIANT_EVALUATION_RESPONSE_TYPE,
+						Response: &evaluation.EvaluationResponse_VariantResponse{
+							VariantResponse: &evaluation.VariantEvaluationResponse{Match: true, VariantKey: "bar"},
+						},
+					},
+				},
+			}
+
+			mockClient := offlipt.NewMockClient(t)
+
+			mockClient.EXPECT().Batch(mock.Anything, &evaluation.BatchEvaluationRequest{
+				RequestId: reqID,
+				Requests: []*evaluation.EvaluationRequest{
+					{FlagKey: "foo", NamespaceKey: "foo-namespace", RequestId: reqID, EntityId: entityID, Context: ec},
+					{FlagKey: "bar", NamespaceKey: "foo-namespace", RequestId: reqID, EntityId: entityID, Context: ec},
+				},
+			}).Return(expected, tt.err)
+
+			s := &Service{
+				client: mockClient,
+			}
+
+			evalCtx := map[string]interface{}{
+				"requestID":     reqID,
+				of.TargetingKey: entityID,
+			}
+
+			actual, err := s.Batch(context.Background(), "foo-namespace", []string{"foo", "bar"}, evalCtx)
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expected, actual)
+			}
+		})
+	}
+}
+
 func TestEvaluateInvalidContext(t *testing.T) {
 	s := &Service{}
 
@@ -239,3 +495,20 @@ func TestLoadTLSCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshot(t *testing.T) {
+	expected := &local.Snapshot{Flags: map[string]*flipt.Flag{"foo": {Key: "foo"}}}
+
+	s := New(WithClient(local.NewClient(local.LoaderFunc(func(_ context.Context, namespaceKey string) (*local.Snapshot, error) {
+		assert.Equal(t, "foo-namespace", namespaceKey)
+
+		return expected, nil
+	}))))
+
+	actual, err := s.Snapshot(context.Background(), "foo-namespace")
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = (&Service{client: offlipt.NewMockClient(t)}).Snapshot(context.Background(), "foo-namespace")
+	assert.EqualError(t, err, of.NewGeneralResolutionError("snapshots are not supported by the client").Error())
+}